@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/pkg/data_structures/quadtree"
+	"github.com/pechorka/illuminate-game-jam/pkg/rlutils"
+)
+
+var (
+	debugQuadtreeColor = rl.Color{R: 0, G: 121, B: 241, A: 160}
+	debugBoundsColor   = rl.Magenta
+	debugRangeColor    = rl.Yellow
+	debugTargetColor   = rl.Red
+	debugRouteColor    = rl.SkyBlue
+	debugFontSize      = int32(10)
+)
+
+// debugOverlay is toggled with F3 and shows collision internals
+type debugOverlay struct {
+	enabled bool
+	queries int // quadtree queries made during the previous frame
+}
+
+type boundable interface {
+	Boundaries() rl.Rectangle
+}
+
+func (gs *gameState) renderDebugOverlay() {
+	if !gs.debug.enabled {
+		return
+	}
+
+	nodes := 0
+	gs.quadtree.Walk(func(node *quadtree.Quadtree, depth int) {
+		nodes++
+		rl.DrawRectangleLinesEx(node.Bounds, 1, debugQuadtreeColor)
+		if node.Len() > 0 {
+			rl.DrawText(
+				strconv.Itoa(node.Len()),
+				int32(node.Bounds.X)+2, int32(node.Bounds.Y)+2,
+				debugFontSize, debugQuadtreeColor,
+			)
+		}
+	})
+
+	drawBoundaries(gs.flares)
+	drawBoundaries(gs.grenades)
+	drawBoundaries(gs.projectiles)
+	drawBoundaries(gs.enemies)
+	drawBoundaries(gs.soldiers)
+	drawBoundaries(gs.obstacles)
+
+	for _, e := range gs.enemies {
+		from := rlutils.RectangleCenter(e.Boundaries())
+		for _, waypoint := range e.GetRoute().Waypoints {
+			rl.DrawLineV(from, waypoint, debugRouteColor)
+			from = waypoint
+		}
+	}
+
+	for _, s := range gs.soldiers {
+		rl.DrawCircleLines(int32(s.Pos.X), int32(s.Pos.Y), s.Range(), debugRangeColor)
+		if s.Target != nil {
+			rl.DrawLineV(s.Pos, s.Target.GetPos(), debugTargetColor)
+		}
+	}
+
+	entities := len(gs.flares) + len(gs.grenades) + len(gs.projectiles) + len(gs.enemies) + len(gs.soldiers) + len(gs.obstacles)
+	renderDebugCounters(
+		"Entities: "+strconv.Itoa(entities),
+		"Quadtree nodes: "+strconv.Itoa(nodes),
+		"Queries per frame: "+strconv.Itoa(gs.debug.queries),
+		fmt.Sprintf("Frame time: %.2fms (%d FPS)", rl.GetFrameTime()*1000, rl.GetFPS()),
+		fmt.Sprintf("Director: %s, stress %.2f, %.2f kills/s", gs.director.Phase(), gs.director.Stress(), gs.director.KillRate()),
+	)
+}
+
+func drawBoundaries[T boundable](items []T) {
+	for _, item := range items {
+		rl.DrawRectangleLinesEx(item.Boundaries(), 1, debugBoundsColor)
+	}
+}
+
+func renderDebugCounters(counters ...string) {
+	x := int32(10)
+	y := int32(helpLabelInitialPos.Y) + 40
+	fontSize := int32(20)
+	for _, counter := range counters {
+		width := rl.MeasureText(counter, fontSize)
+		rl.DrawRectangle(x-5, y-2, width+10, fontSize+4, rl.Fade(rl.Black, 0.6))
+		rl.DrawText(counter, x, y, fontSize, rl.Green)
+		y += fontSize + 6
+	}
+}
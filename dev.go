@@ -0,0 +1,133 @@
+package main
+
+import (
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/boss"
+	"github.com/pechorka/illuminate-game-jam/pkg/filewatch"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	defaultBalancePath = "assets/balance.json"
+	devPollInterval    = 500 * time.Millisecond
+)
+
+// devReloader watches balance and textures on disk while game is running
+type devReloader struct {
+	watcher     *filewatch.Watcher
+	balancePath string
+}
+
+func newDevReloader(balancePath string, ga *gameAssets) *devReloader {
+	paths := []string{defaultBalancePath, balancePath}
+	paths = append(paths, maps.Keys(ga.textures())...)
+	return &devReloader{
+		watcher:     filewatch.New(devPollInterval, paths...),
+		balancePath: balancePath,
+	}
+}
+
+func (gs *gameState) hotReload() {
+	textures := gs.assets.textures()
+	balanceChanged := false
+	for _, path := range gs.dev.watcher.Changed() {
+		if path == defaultBalancePath || path == gs.dev.balancePath {
+			balanceChanged = true
+			continue
+		}
+		if texture, ok := textures[path]; ok {
+			gs.reloadTexture(path, texture)
+		}
+	}
+
+	if balanceChanged {
+		gs.reloadBalance()
+	}
+}
+
+func (gs *gameState) reloadTexture(path string, texture *rl.Texture2D) {
+	next, err := loadTexture(path)
+	if err != nil {
+		// keep the previous texture until the file is fixed
+		rl.TraceLog(rl.LogWarning, "Texture %s wasn't reloaded: %v", path, err)
+		return
+	}
+	rl.TraceLog(rl.LogInfo, "Reloading texture %s", path)
+	old := *texture
+	*texture = next
+	gs.replaceTexture(old, next)
+	rl.UnloadTexture(old)
+}
+
+// replaceTexture points everything drawn with old texture to next one,
+// including packs that are telegraphed but not spawned yet
+func (gs *gameState) replaceTexture(old, next rl.Texture2D) {
+	type sprited interface {
+		GetSprite() *ecs.Sprite
+	}
+	replaceEnemies := func(list []enemies.Enemy) {
+		for _, e := range list {
+			if s, ok := e.(sprited); ok && s.GetSprite().Texture.ID == old.ID {
+				s.GetSprite().Texture = next
+			}
+		}
+	}
+	replaceEnemies(gs.enemies)
+	for _, p := range gs.pending {
+		replaceEnemies(p.pack)
+	}
+	for _, s := range gs.soldiers {
+		if s.Texture.ID == old.ID {
+			s.Texture = next
+		}
+		if s.Levelup.ID == old.ID {
+			s.Levelup = next
+		}
+	}
+	if gs.vip != nil && gs.vip.Texture.ID == old.ID {
+		gs.vip.Texture = next
+	}
+}
+
+func (gs *gameState) reloadBalance() {
+	next, err := loadBalance(gs.dev.balancePath)
+	if err != nil {
+		// keep playing with the previous balance until the file is fixed
+		rl.TraceLog(rl.LogWarning, "Balance wasn't reloaded: %v", err)
+		return
+	}
+	rl.TraceLog(rl.LogInfo, "Reloading balance")
+	gs.loadedBalance = next
+	gs.applyBalance(next.WithDifficulty(gs.difficulty.stats(next.Difficulties)))
+}
+
+// applyBalance replaces balance and rescales stats of alive enemies and soldiers
+func (gs *gameState) applyBalance(next *balance.Balance) {
+	prev := gs.balance
+	gs.balance = next
+	gs.spawnRate = next.InitialSpawnRate
+	gs.director.Rebalance(next.Director)
+	if gs.objective != nil {
+		gs.objective.Rebalance(next.Objectives)
+	}
+	gs.enemyRegistry = newEnemyRegistry(gs.assets, next)
+
+	for _, e := range gs.enemies {
+		e.Rebalance(enemyStats(prev, e.Name()), enemyStats(next, e.Name()))
+	}
+	for _, s := range gs.soldiers {
+		s.Rebalance(next.Soldier)
+	}
+}
+
+func enemyStats(b *balance.Balance, name string) balance.Enemy {
+	if name == boss.Name {
+		return b.Boss.Stats()
+	}
+	return b.Enemies[name]
+}
@@ -0,0 +1,587 @@
+// Package balance contains game balance that designers can tune without recompiling.
+//
+// Defaults are embedded into the binary, an override file can change any of
+// the default values, but can't add new keys.
+package balance
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+// Version of balance file format this build understands
+const Version = 1
+
+// Range is a range random stat is picked from
+type Range struct {
+	From float32 `json:"from"`
+	To   float32 `json:"to"`
+}
+
+// Scale multiplies both ends of the range
+func (r Range) Scale(by float32) Range {
+	return Range{From: r.From * by, To: r.To * by}
+}
+
+type Enemy struct {
+	Speed    Range    `json:"speed"`
+	Health   Range    `json:"health"`
+	Damage   Range    `json:"damage"`
+	Spawn    Spawn    `json:"spawn"`
+	Steering Steering `json:"steering"`
+	// Ranged is set for enemies that shoot at soldiers
+	Ranged *Ranged `json:"ranged,omitempty"`
+	// Split is set for enemies that split into other enemies on death
+	Split *Split `json:"split,omitempty"`
+	// LightEater is set for enemies that are attracted to flares and drain them
+	LightEater *LightEater `json:"lightEater,omitempty"`
+}
+
+// Spawn describes how often enemy is spawned compared to other enemies
+type Spawn struct {
+	Weight          float32 `json:"weight"`
+	WeightPerMinute float32 `json:"weightPerMinute"` // how weight changes with game time
+	UnlockAt        float32 `json:"unlockAt"`        // seconds of game time before first spawn
+	Pack            int     `json:"pack"`            // how many enemies are spawned together
+}
+
+// Steering weights blend forces that move enemy
+type Steering struct {
+	Seek       float32 `json:"seek"`       // towards target
+	Flee       float32 `json:"flee"`       // away from flares
+	Separation float32 `json:"separation"` // away from other enemies
+	Arrival    float32 `json:"arrival"`    // radius enemy slows down within before reaching target
+}
+
+// Ranged describes enemy attack from distance
+type Ranged struct {
+	Range           float32 `json:"range"`
+	Rate            float32 `json:"rate"`            // seconds between shots
+	ProjectileSpeed float32 `json:"projectileSpeed"` // pixels per frame
+}
+
+// Split describes enemies that appear when enemy dies
+type Split struct {
+	Into  string `json:"into"` // name of enemy
+	Count int    `json:"count"`
+}
+
+type LightEater struct {
+	Drain float32 `json:"drain"` // flare radius is multiplied by it every frame
+}
+
+// Boss appears every Every seconds of game time and goes through phases as it loses health
+type Boss struct {
+	Speed    Range    `json:"speed"`
+	Health   Range    `json:"health"`
+	Damage   Range    `json:"damage"`
+	Steering Steering `json:"steering"`
+
+	Every            float32     `json:"every"`
+	RewardMultiplier int         `json:"rewardMultiplier"`
+	ChargeSpeed      float32     `json:"chargeSpeed"` // speed multiplier while charging
+	Phases           []BossPhase `json:"phases"`
+}
+
+// Stats returns boss stats in the same form as other enemies have
+func (b Boss) Stats() Enemy {
+	return Enemy{Speed: b.Speed, Health: b.Health, Damage: b.Damage, Steering: b.Steering}
+}
+
+// BossPhase starts once boss health drops to HealthBelow part of max health
+type BossPhase struct {
+	HealthBelow   float32 `json:"healthBelow"`
+	Summon        int     `json:"summon"`        // basic minions summoned at the start of phase
+	Charge        float32 `json:"charge"`        // seconds of charging at soldiers
+	FlareImmunity float32 `json:"flareImmunity"` // seconds of ignoring flares
+}
+
+// Arena describes obstacles generated at the start of every run and how enemies walk around them
+type Arena struct {
+	CellSize  float32 `json:"cellSize"` // pixels, obstacles and paths are aligned to cells
+	Walls     int     `json:"walls"`
+	Rocks     int     `json:"rocks"`
+	Ruins     int     `json:"ruins"`
+	Nests     int     `json:"nests"`     // free places enemies crawl out of
+	FlareCost float32 `json:"flareCost"` // how many cells of detour enemies take to avoid one cell of full flare light
+}
+
+// Effect is a timed status effect
+type Effect struct {
+	Kind     string  `json:"kind"`     // burn, slow, stun or blind
+	Strength float32 `json:"strength"` // burn: damage per second, slow and blind: part of speed or shooting range taken
+	Duration float32 `json:"duration"` // seconds
+}
+
+// Effects are applied by consumables and flares to whoever is in their area
+type Effects struct {
+	Grenade []Effect `json:"grenade"`
+	Flare   []Effect `json:"flare"` // only soldiers are affected by flares
+}
+
+// Elite enemies roll affixes when they spawn once game time reaches UnlockAt
+type Elite struct {
+	UnlockAt        float32  `json:"unlockAt"`        // seconds
+	Chance          float32  `json:"chance"`          // of spawned enemy being elite right after unlock
+	ChancePerMinute float32  `json:"chancePerMinute"` // added to chance every minute after unlock
+	MaxAffixes      int      `json:"maxAffixes"`
+	RewardPerAffix  float32  `json:"rewardPerAffix"` // part of base reward added for every affix
+	Armour          Range    `json:"armour"`         // damage taken off every hit, scaled with game time
+	Vampiric        float32  `json:"vampiric"`       // part of damage dealt to soldiers that is healed
+	Speed           float32  `json:"speed"`          // speed multiplier of fast elites
+	Explosion       []Effect `json:"explosion"`      // applied around explosive elites when they die
+}
+
+// Director paces spawns: pressure builds up until soldiers are stressed or BuildUp runs out,
+// peaks for Peak seconds, then relaxes until soldiers calm down
+type Director struct {
+	BuildUp     float32 `json:"buildUp"`     // max seconds of build up
+	Peak        float32 `json:"peak"`        // seconds
+	Relax       float32 `json:"relax"`       // min seconds, relax lasts up to twice as long while soldiers are stressed
+	PeakStress  float32 `json:"peakStress"`  // stress that ends build up early
+	RelaxStress float32 `json:"relaxStress"` // stress soldiers have to calm down to before next build up
+	PeakRate    float32 `json:"peakRate"`    // spawn interval multiplier at peak, build up goes from 1 to it
+	RelaxRate   float32 `json:"relaxRate"`   // spawn interval multiplier during relax
+	KillWindow  float32 `json:"killWindow"`  // seconds kill rate is averaged over
+	Stress      Stress  `json:"stress"`
+	Zones       Zones   `json:"zones"` // where enemies come from in every phase
+}
+
+// Stress weights what makes soldiers stressed
+type Stress struct {
+	Health   float32 `json:"health"`   // part of health soldiers lost
+	Darkness float32 `json:"darkness"` // part of arena not lit by flares
+	Kills    float32 `json:"kills"`    // keeping up with spawns, lowers stress
+}
+
+// Zones are weights of spawn zones for every director phase
+type Zones struct {
+	BuildUp ZoneWeights `json:"buildUp"`
+	Peak    ZoneWeights `json:"peak"`
+	Relax   ZoneWeights `json:"relax"`
+}
+
+// ZoneWeights are chances of spawn zones to be picked relative to each other
+type ZoneWeights struct {
+	Edge   float32 `json:"edge"`
+	Corner float32 `json:"corner"`
+	Nest   float32 `json:"nest"`
+}
+
+type Soldier struct {
+	Speed              float32 `json:"speed"`
+	Health             float32 `json:"health"`
+	Damage             float32 `json:"damage"`
+	ShootingRange      float32 `json:"shootingRange"`
+	ShootingRate       float32 `json:"shootingRate"` // seconds between shots
+	LevelUpThreshold   int     `json:"levelUpThreshold"`
+	NextLevelThreshold int     `json:"nextLevelThreshold"` // percent more than previous level
+	StatUp             int     `json:"statUp"`             // increase by percent when leveling up
+}
+
+type ShopItem struct {
+	Price int `json:"price"`
+	Count int `json:"count"`
+}
+
+// Escort is a contract bought in the shop: VIP has to survive for Duration seconds
+type Escort struct {
+	Price    int     `json:"price"`
+	Duration float32 `json:"duration"` // seconds
+	Health   float32 `json:"health"`
+	Speed    float32 `json:"speed"`    // pixels per frame
+	Payout   int     `json:"payout"`   // money and score for escorted VIP
+	Penalty  int     `json:"penalty"`  // money lost with VIP
+	Priority float32 `json:"priority"` // enemies go for VIP unless nearest soldier is this many times closer
+}
+
+// Contract is a timed modifier signed in the shop, it pays Cash and Score once Duration is over
+type Contract struct {
+	Duration float32 `json:"duration"` // seconds
+	Cash     int     `json:"cash"`
+	Score    int     `json:"score"`
+}
+
+type Contracts struct {
+	Frenzy       Contract `json:"frenzy"`       // enemies spawn more often
+	FrenzySpawns float32  `json:"frenzySpawns"` // how many times more often enemies spawn during frenzy
+	Blackout     Contract `json:"blackout"`     // flares can't be used
+	Elites       Contract `json:"elites"`       // every spawned enemy is elite
+}
+
+// Objectives are victory conditions of endless mode, player picks one before the run
+type Objectives struct {
+	Dawn     float32 `json:"dawn"`     // seconds of night to survive
+	Twilight float32 `json:"twilight"` // seconds before dawn the sky starts to brighten
+	Retreat  float32 `json:"retreat"`  // seconds enemies have to run away at dawn before the run is won
+	Kills    int     `json:"kills"`
+	Bosses   int     `json:"bosses"`
+}
+
+// Difficulty is a preset chosen before the run, every field multiplies part of the balance
+type Difficulty struct {
+	Stats       float32 `json:"stats"`       // enemy and boss health and damage, so everything enemies scale with time
+	SpawnRate   float32 `json:"spawnRate"`   // initialSpawnRate and spawnRateLimit, less means more enemies
+	Consumables float32 `json:"consumables"` // initialFlareCount and initialGrenadeCount
+	Prices      float32 `json:"prices"`      // shop prices
+	Rewards     float32 `json:"rewards"`     // score and money earned for kills
+}
+
+type Difficulties struct {
+	Easy      Difficulty `json:"easy"`
+	Normal    Difficulty `json:"normal"`
+	Hard      Difficulty `json:"hard"`
+	Nightmare Difficulty `json:"nightmare"`
+}
+
+type Balance struct {
+	Version int `json:"version"`
+
+	Enemies  map[string]Enemy    `json:"enemies"`
+	Boss     Boss                `json:"boss"`
+	Arena    Arena               `json:"arena"`
+	Effects  Effects             `json:"effects"`
+	Elite    Elite               `json:"elite"`
+	Director Director            `json:"director"`
+	Soldier  Soldier             `json:"soldier"`
+	Shop     map[string]ShopItem `json:"shop"`
+	Escort   Escort              `json:"escort"`
+
+	Contracts Contracts `json:"contracts"`
+
+	Objectives   Objectives   `json:"objectives"`
+	Difficulties Difficulties `json:"difficulties"`
+
+	InitialFlareCount   int     `json:"initialFlareCount"`
+	InitialGrenadeCount int     `json:"initialGrenadeCount"`
+	InitialSpawnRate    float32 `json:"initialSpawnRate"`
+	SpawnRateLimit      float32 `json:"spawnRateLimit"`
+}
+
+// WithDifficulty returns copy of balance scaled by difficulty preset
+func (b *Balance) WithDifficulty(d Difficulty) *Balance {
+	scaled := *b
+
+	scaled.Enemies = make(map[string]Enemy, len(b.Enemies))
+	for name, e := range b.Enemies {
+		e.Health = e.Health.Scale(d.Stats)
+		e.Damage = e.Damage.Scale(d.Stats)
+		scaled.Enemies[name] = e
+	}
+	scaled.Boss.Health = b.Boss.Health.Scale(d.Stats)
+	scaled.Boss.Damage = b.Boss.Damage.Scale(d.Stats)
+
+	scaled.Shop = make(map[string]ShopItem, len(b.Shop))
+	for name, item := range b.Shop {
+		item.Price = scaleInt(item.Price, d.Prices)
+		scaled.Shop[name] = item
+	}
+
+	scaled.Escort.Price = scaleInt(b.Escort.Price, d.Prices)
+
+	scaled.InitialFlareCount = scaleInt(b.InitialFlareCount, d.Consumables)
+	scaled.InitialGrenadeCount = scaleInt(b.InitialGrenadeCount, d.Consumables)
+	scaled.InitialSpawnRate = b.InitialSpawnRate * d.SpawnRate
+	scaled.SpawnRateLimit = b.SpawnRateLimit * d.SpawnRate
+	return &scaled
+}
+
+func scaleInt(v int, by float32) int {
+	return int(math.Round(float64(float32(v) * by)))
+}
+
+// Load parses defaults and applies override file on top of them.
+// Missing override file is not an error, defaults are used as is.
+func Load(defaults []byte, overridePath string) (*Balance, error) {
+	var base map[string]any
+	if err := json.Unmarshal(defaults, &base); err != nil {
+		return nil, fmt.Errorf("default balance: %w", err)
+	}
+
+	override, err := os.ReadFile(overridePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return Parse(defaults)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("balance override: %w", err)
+	}
+
+	var patch map[string]any
+	if err := json.Unmarshal(override, &patch); err != nil {
+		return nil, fmt.Errorf("balance override %s: %w", overridePath, err)
+	}
+	if err := merge(base, patch, ""); err != nil {
+		return nil, fmt.Errorf("balance override %s: %w", overridePath, err)
+	}
+
+	merged, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	b, err := Parse(merged)
+	if err != nil {
+		return nil, fmt.Errorf("balance override %s: %w", overridePath, err)
+	}
+	return b, nil
+}
+
+// Parse parses and validates complete balance file
+func Parse(data []byte) (*Balance, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var b Balance
+	if err := dec.Decode(&b); err != nil {
+		return nil, err
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// merge copies values from src to dst, src can only contain keys that exist in dst
+func merge(dst, src map[string]any, path string) error {
+	for key, srcVal := range src {
+		keyPath := strings.TrimPrefix(path+"."+key, ".")
+		dstVal, ok := dst[key]
+		if !ok {
+			return fmt.Errorf("%s: unknown key", keyPath)
+		}
+
+		dstMap, dstIsMap := dstVal.(map[string]any)
+		srcMap, srcIsMap := srcVal.(map[string]any)
+		switch {
+		case dstIsMap && srcIsMap:
+			if err := merge(dstMap, srcMap, keyPath); err != nil {
+				return err
+			}
+		case dstIsMap != srcIsMap:
+			return fmt.Errorf("%s: expected %s", keyPath, kind(dstVal))
+		default:
+			dst[key] = srcVal
+		}
+	}
+	return nil
+}
+
+func kind(v any) string {
+	if _, ok := v.(map[string]any); ok {
+		return "object"
+	}
+	return "value"
+}
+
+// Validate returns all problems found in balance, one per line
+func (b *Balance) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(b.Version == Version, "version: %d is not supported, expected %d", b.Version, Version)
+
+	checkSteering := func(path string, s Steering) {
+		check(s.Seek >= 0, "%s.seek: %v can't be negative", path, s.Seek)
+		check(s.Flee >= 0, "%s.flee: %v can't be negative", path, s.Flee)
+		check(s.Separation >= 0, "%s.separation: %v can't be negative", path, s.Separation)
+		check(s.Arrival >= 0, "%s.arrival: %v can't be negative", path, s.Arrival)
+	}
+	checkRange := func(path string, r Range, allowZero bool) {
+		check(r.From <= r.To, "%s: from (%v) is greater than to (%v)", path, r.From, r.To)
+		if allowZero {
+			check(r.From >= 0, "%s: from (%v) can't be negative", path, r.From)
+		} else {
+			check(r.From > 0, "%s: from (%v) must be positive", path, r.From)
+		}
+	}
+
+	check(len(b.Enemies) > 0, "enemies: at least one enemy is required")
+	enemyNames := maps.Keys(b.Enemies)
+	slices.Sort(enemyNames)
+	for _, name := range enemyNames {
+		e := b.Enemies[name]
+		checkRange("enemies."+name+".speed", e.Speed, false)
+		checkRange("enemies."+name+".health", e.Health, false)
+		checkRange("enemies."+name+".damage", e.Damage, true)
+		checkSteering("enemies."+name+".steering", e.Steering)
+		path := "enemies." + name + ".spawn"
+		check(e.Spawn.Weight >= 0, "%s.weight: %v can't be negative", path, e.Spawn.Weight)
+		check(e.Spawn.UnlockAt >= 0, "%s.unlockAt: %v can't be negative", path, e.Spawn.UnlockAt)
+		check(e.Spawn.Pack > 0, "%s.pack: %v must be positive", path, e.Spawn.Pack)
+		if r := e.Ranged; r != nil {
+			path := "enemies." + name + ".ranged"
+			check(r.Range > 0, "%s.range: %v must be positive", path, r.Range)
+			check(r.Rate > 0, "%s.rate: %v must be positive", path, r.Rate)
+			check(r.ProjectileSpeed > 0, "%s.projectileSpeed: %v must be positive", path, r.ProjectileSpeed)
+		}
+		if split := e.Split; split != nil {
+			path := "enemies." + name + ".split"
+			_, ok := b.Enemies[split.Into]
+			check(ok, "%s.into: unknown enemy %q", path, split.Into)
+			check(split.Into != name, "%s.into: enemy can't split into itself", path)
+			check(split.Count > 0, "%s.count: %v must be positive", path, split.Count)
+		}
+		if le := e.LightEater; le != nil {
+			check(le.Drain > 0 && le.Drain < 1,
+				"enemies.%s.lightEater.drain: %v must be between 0 and 1", name, le.Drain)
+		}
+	}
+
+	boss := b.Boss
+	checkRange("boss.speed", boss.Speed, false)
+	checkRange("boss.health", boss.Health, false)
+	checkRange("boss.damage", boss.Damage, true)
+	checkSteering("boss.steering", boss.Steering)
+	check(boss.Every > 0, "boss.every: %v must be positive", boss.Every)
+	check(boss.RewardMultiplier > 0, "boss.rewardMultiplier: %v must be positive", boss.RewardMultiplier)
+	check(boss.ChargeSpeed > 0, "boss.chargeSpeed: %v must be positive", boss.ChargeSpeed)
+	for i, p := range boss.Phases {
+		path := fmt.Sprintf("boss.phases[%d]", i)
+		check(p.HealthBelow > 0 && p.HealthBelow < 1, "%s.healthBelow: %v must be between 0 and 1", path, p.HealthBelow)
+		if i > 0 {
+			prev := boss.Phases[i-1].HealthBelow
+			check(p.HealthBelow < prev, "%s.healthBelow: %v must be less than previous phase (%v)", path, p.HealthBelow, prev)
+		}
+		check(p.Summon >= 0, "%s.summon: %v can't be negative", path, p.Summon)
+		check(p.Charge >= 0, "%s.charge: %v can't be negative", path, p.Charge)
+		check(p.FlareImmunity >= 0, "%s.flareImmunity: %v can't be negative", path, p.FlareImmunity)
+	}
+
+	a := b.Arena
+	check(a.CellSize >= 8, "arena.cellSize: %v must be at least 8", a.CellSize)
+	check(a.Walls >= 0, "arena.walls: %v can't be negative", a.Walls)
+	check(a.Rocks >= 0, "arena.rocks: %v can't be negative", a.Rocks)
+	check(a.Ruins >= 0, "arena.ruins: %v can't be negative", a.Ruins)
+	check(a.Nests >= 0, "arena.nests: %v can't be negative", a.Nests)
+	check(a.FlareCost >= 0, "arena.flareCost: %v can't be negative", a.FlareCost)
+
+	checkEffects := func(path string, effects []Effect) {
+		for i, e := range effects {
+			path := fmt.Sprintf("%s[%d]", path, i)
+			check(e.Duration > 0, "%s.duration: %v must be positive", path, e.Duration)
+			switch e.Kind {
+			case "burn":
+				check(e.Strength > 0, "%s.strength: %v must be positive", path, e.Strength)
+			case "slow", "blind":
+				check(e.Strength > 0 && e.Strength <= 1, "%s.strength: %v must be between 0 and 1", path, e.Strength)
+			case "stun":
+			default:
+				check(false, "%s.kind: unknown effect %q", path, e.Kind)
+			}
+		}
+	}
+	checkEffects("effects.grenade", b.Effects.Grenade)
+	checkEffects("effects.flare", b.Effects.Flare)
+
+	el := b.Elite
+	check(el.UnlockAt >= 0, "elite.unlockAt: %v can't be negative", el.UnlockAt)
+	check(el.Chance >= 0 && el.Chance <= 1, "elite.chance: %v must be between 0 and 1", el.Chance)
+	check(el.ChancePerMinute >= 0, "elite.chancePerMinute: %v can't be negative", el.ChancePerMinute)
+	check(el.MaxAffixes > 0, "elite.maxAffixes: %v must be positive", el.MaxAffixes)
+	check(el.RewardPerAffix >= 0, "elite.rewardPerAffix: %v can't be negative", el.RewardPerAffix)
+	checkRange("elite.armour", el.Armour, true)
+	check(el.Vampiric >= 0 && el.Vampiric <= 1, "elite.vampiric: %v must be between 0 and 1", el.Vampiric)
+	check(el.Speed >= 1, "elite.speed: %v must be at least 1", el.Speed)
+	checkEffects("elite.explosion", el.Explosion)
+
+	d := b.Director
+	check(d.BuildUp > 0, "director.buildUp: %v must be positive", d.BuildUp)
+	check(d.Peak > 0, "director.peak: %v must be positive", d.Peak)
+	check(d.Relax > 0, "director.relax: %v must be positive", d.Relax)
+	check(d.PeakStress > 0 && d.PeakStress <= 1, "director.peakStress: %v must be between 0 and 1", d.PeakStress)
+	check(d.RelaxStress >= 0 && d.RelaxStress < d.PeakStress,
+		"director.relaxStress: %v must be between 0 and peakStress (%v)", d.RelaxStress, d.PeakStress)
+	check(d.PeakRate > 0 && d.PeakRate <= 1, "director.peakRate: %v must be between 0 and 1", d.PeakRate)
+	check(d.RelaxRate >= 1, "director.relaxRate: %v must be at least 1", d.RelaxRate)
+	check(d.KillWindow > 0, "director.killWindow: %v must be positive", d.KillWindow)
+	check(d.Stress.Health >= 0, "director.stress.health: %v can't be negative", d.Stress.Health)
+	check(d.Stress.Darkness >= 0, "director.stress.darkness: %v can't be negative", d.Stress.Darkness)
+	check(d.Stress.Kills >= 0, "director.stress.kills: %v can't be negative", d.Stress.Kills)
+	check(d.Stress.Health+d.Stress.Darkness > 0, "director.stress: health or darkness must be positive")
+	checkZones := func(path string, w ZoneWeights) {
+		check(w.Edge >= 0, "%s.edge: %v can't be negative", path, w.Edge)
+		check(w.Corner >= 0, "%s.corner: %v can't be negative", path, w.Corner)
+		check(w.Nest >= 0, "%s.nest: %v can't be negative", path, w.Nest)
+		check(w.Edge+w.Corner+w.Nest > 0, "%s: at least one weight must be positive", path)
+	}
+	checkZones("director.zones.buildUp", d.Zones.BuildUp)
+	checkZones("director.zones.peak", d.Zones.Peak)
+	checkZones("director.zones.relax", d.Zones.Relax)
+
+	s := b.Soldier
+	check(s.Speed >= 0, "soldier.speed: %v can't be negative", s.Speed)
+	check(s.Health > 0, "soldier.health: %v must be positive", s.Health)
+	check(s.Damage >= 0, "soldier.damage: %v can't be negative", s.Damage)
+	check(s.ShootingRange > 0, "soldier.shootingRange: %v must be positive", s.ShootingRange)
+	check(s.ShootingRate > 0, "soldier.shootingRate: %v must be positive", s.ShootingRate)
+	check(s.LevelUpThreshold > 0, "soldier.levelUpThreshold: %v must be positive", s.LevelUpThreshold)
+	check(s.NextLevelThreshold >= 0, "soldier.nextLevelThreshold: %v can't be negative", s.NextLevelThreshold)
+	check(s.StatUp >= 0 && s.StatUp < 100, "soldier.statUp: %v must be between 0 and 99 percent", s.StatUp)
+
+	itemNames := maps.Keys(b.Shop)
+	slices.Sort(itemNames)
+	for _, name := range itemNames {
+		item := b.Shop[name]
+		check(item.Price >= 0, "shop.%s.price: %v can't be negative", name, item.Price)
+		check(item.Count > 0, "shop.%s.count: %v must be positive", name, item.Count)
+	}
+
+	es := b.Escort
+	check(es.Price >= 0, "escort.price: %v can't be negative", es.Price)
+	check(es.Duration > 0, "escort.duration: %v must be positive", es.Duration)
+	check(es.Health > 0, "escort.health: %v must be positive", es.Health)
+	check(es.Speed >= 0, "escort.speed: %v can't be negative", es.Speed)
+	check(es.Payout >= 0, "escort.payout: %v can't be negative", es.Payout)
+	check(es.Penalty >= 0, "escort.penalty: %v can't be negative", es.Penalty)
+	check(es.Priority >= 1, "escort.priority: %v must be at least 1", es.Priority)
+
+	checkContract := func(path string, c Contract) {
+		check(c.Duration > 0, "%s.duration: %v must be positive", path, c.Duration)
+		check(c.Cash >= 0, "%s.cash: %v can't be negative", path, c.Cash)
+		check(c.Score >= 0, "%s.score: %v can't be negative", path, c.Score)
+	}
+	checkContract("contracts.frenzy", b.Contracts.Frenzy)
+	check(b.Contracts.FrenzySpawns >= 1, "contracts.frenzySpawns: %v must be at least 1", b.Contracts.FrenzySpawns)
+	checkContract("contracts.blackout", b.Contracts.Blackout)
+	checkContract("contracts.elites", b.Contracts.Elites)
+
+	o := b.Objectives
+	check(o.Dawn > 0, "objectives.dawn: %v must be positive", o.Dawn)
+	check(o.Twilight >= 0 && o.Twilight <= o.Dawn,
+		"objectives.twilight: %v must be between 0 and dawn (%v)", o.Twilight, o.Dawn)
+	check(o.Retreat >= 0, "objectives.retreat: %v can't be negative", o.Retreat)
+	check(o.Kills > 0, "objectives.kills: %v must be positive", o.Kills)
+	check(o.Bosses > 0, "objectives.bosses: %v must be positive", o.Bosses)
+
+	checkDifficulty := func(path string, d Difficulty) {
+		check(d.Stats > 0, "%s.stats: %v must be positive", path, d.Stats)
+		check(d.SpawnRate > 0, "%s.spawnRate: %v must be positive", path, d.SpawnRate)
+		check(d.Consumables >= 0, "%s.consumables: %v can't be negative", path, d.Consumables)
+		check(d.Prices >= 0, "%s.prices: %v can't be negative", path, d.Prices)
+		check(d.Rewards >= 0, "%s.rewards: %v can't be negative", path, d.Rewards)
+	}
+	checkDifficulty("difficulties.easy", b.Difficulties.Easy)
+	checkDifficulty("difficulties.normal", b.Difficulties.Normal)
+	checkDifficulty("difficulties.hard", b.Difficulties.Hard)
+	checkDifficulty("difficulties.nightmare", b.Difficulties.Nightmare)
+
+	check(b.InitialFlareCount >= 0, "initialFlareCount: %v can't be negative", b.InitialFlareCount)
+	check(b.InitialGrenadeCount >= 0, "initialGrenadeCount: %v can't be negative", b.InitialGrenadeCount)
+	check(b.InitialSpawnRate > 0, "initialSpawnRate: %v must be positive", b.InitialSpawnRate)
+	check(b.SpawnRateLimit > 0, "spawnRateLimit: %v must be positive", b.SpawnRateLimit)
+	check(b.SpawnRateLimit <= b.InitialSpawnRate,
+		"spawnRateLimit: %v is greater than initialSpawnRate (%v)", b.SpawnRateLimit, b.InitialSpawnRate)
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,212 @@
+package balance
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readDefaults(t *testing.T) []byte {
+	t.Helper()
+	defaults, err := os.ReadFile("../../assets/balance.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return defaults
+}
+
+func writeOverride(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "balance.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("defaults are valid", func(t *testing.T) {
+		b, err := Load(readDefaults(t), filepath.Join(t.TempDir(), "missing.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"basic", "fast", "tank"} {
+			if _, ok := b.Enemies[name]; !ok {
+				t.Errorf("enemy %s is missing", name)
+			}
+		}
+		for _, name := range []string{"flare", "grenade"} {
+			if _, ok := b.Shop[name]; !ok {
+				t.Errorf("shop item %s is missing", name)
+			}
+		}
+	})
+
+	t.Run("override changes only given values", func(t *testing.T) {
+		path := writeOverride(t, `{"enemies": {"tank": {"speed": {"to": 5}}}, "initialFlareCount": 1}`)
+
+		b, err := Load(readDefaults(t), path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tank := b.Enemies["tank"]
+		if tank.Speed.To != 5 {
+			t.Errorf("got tank speed to %v, want 5", tank.Speed.To)
+		}
+		if tank.Speed.From != 0.4 {
+			t.Errorf("got tank speed from %v, want 0.4", tank.Speed.From)
+		}
+		if b.InitialFlareCount != 1 {
+			t.Errorf("got initial flare count %v, want 1", b.InitialFlareCount)
+		}
+		if b.Soldier.Health != 100 {
+			t.Errorf("got soldier health %v, want 100", b.Soldier.Health)
+		}
+	})
+
+	errorCases := []struct {
+		name     string
+		override string
+		wantErr  string
+	}{
+		{
+			name:     "typo in key",
+			override: `{"enemies": {"tnak": {"speed": {"to": 5}}}}`,
+			wantErr:  "enemies.tnak: unknown key",
+		},
+		{
+			name:     "unsupported version",
+			override: `{"version": 2}`,
+			wantErr:  "version: 2 is not supported, expected 1",
+		},
+		{
+			name:     "inverted range",
+			override: `{"enemies": {"fast": {"health": {"from": 20, "to": 10}}}}`,
+			wantErr:  "enemies.fast.health: from (20) is greater than to (10)",
+		},
+		{
+			name:     "value instead of object",
+			override: `{"soldier": 5}`,
+			wantErr:  "soldier: expected object",
+		},
+		{
+			name:     "wrong type",
+			override: `{"initialFlareCount": "many"}`,
+			wantErr:  "initialFlareCount",
+		},
+		{
+			name:     "spawn limit above initial rate",
+			override: `{"spawnRateLimit": 2}`,
+			wantErr:  "spawnRateLimit: 2 is greater than initialSpawnRate (1)",
+		},
+		{
+			name:     "broken json",
+			override: `{"soldier": `,
+			wantErr:  "unexpected end of JSON input",
+		},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeOverride(t, tc.override)
+
+			_, err := Load(readDefaults(t), path)
+			if err == nil {
+				t.Fatalf("got no error, want %q", tc.wantErr)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got error %q, want it to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	b, err := Parse(readDefaults(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Soldier.Health = 0
+	b.Shop["flare"] = ShopItem{Price: -1, Count: 0}
+	b.Enemies["spitter"].Ranged.Rate = 0
+	b.Boss.Phases[1].HealthBelow = 0.9
+	b.Enemies["splitter"].Split.Into = "ghost"
+	b.Arena.CellSize = 4
+	b.Effects.Grenade[0].Kind = "freeze"
+	b.Effects.Flare[0].Strength = 2
+	b.Elite.Chance = 1.5
+	b.Elite.Speed = 0.5
+	b.Director.RelaxStress = 0.9
+	b.Director.Zones.Peak = ZoneWeights{}
+	b.Difficulties.Hard.Stats = 0
+	b.Objectives.Twilight = 900
+	b.Escort.Priority = 0.5
+	b.Contracts.Blackout.Duration = 0
+
+	err = b.Validate()
+	if err == nil {
+		t.Fatal("got no error")
+	}
+	for _, want := range []string{
+		"soldier.health: 0 must be positive",
+		"shop.flare.price: -1 can't be negative",
+		"shop.flare.count: 0 must be positive",
+		"enemies.spitter.ranged.rate: 0 must be positive",
+		"boss.phases[1].healthBelow: 0.9 must be less than previous phase (0.75)",
+		`enemies.splitter.split.into: unknown enemy "ghost"`,
+		"arena.cellSize: 4 must be at least 8",
+		`effects.grenade[0].kind: unknown effect "freeze"`,
+		"effects.flare[0].strength: 2 must be between 0 and 1",
+		"elite.chance: 1.5 must be between 0 and 1",
+		"elite.speed: 0.5 must be at least 1",
+		"director.relaxStress: 0.9 must be between 0 and peakStress (0.6)",
+		"director.zones.peak: at least one weight must be positive",
+		"difficulties.hard.stats: 0 must be positive",
+		"objectives.twilight: 900 must be between 0 and dawn (600)",
+		"escort.priority: 0.5 must be at least 1",
+		"contracts.blackout.duration: 0 must be positive",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestWithDifficulty(t *testing.T) {
+	b, err := Parse(readDefaults(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Difficulty{Stats: 2, SpawnRate: 0.5, Consumables: 0.5, Prices: 1.5, Rewards: 3}
+
+	scaled := b.WithDifficulty(d)
+
+	if got, want := scaled.Enemies["basic"].Health, b.Enemies["basic"].Health.Scale(2); got != want {
+		t.Errorf("enemy health: got %v, want %v", got, want)
+	}
+	if got, want := scaled.Enemies["basic"].Speed, b.Enemies["basic"].Speed; got != want {
+		t.Errorf("enemy speed: got %v, want it unchanged %v", got, want)
+	}
+	if got, want := scaled.Boss.Damage, b.Boss.Damage.Scale(2); got != want {
+		t.Errorf("boss damage: got %v, want %v", got, want)
+	}
+	if got, want := scaled.Shop["flare"].Price, 15; got != want {
+		t.Errorf("flare price: got %v, want %v", got, want)
+	}
+	if got, want := scaled.Escort.Price, 75; got != want {
+		t.Errorf("escort price: got %v, want %v", got, want)
+	}
+	if got, want := scaled.InitialFlareCount, b.InitialFlareCount/2; got != want {
+		t.Errorf("initial flares: got %v, want %v", got, want)
+	}
+	if got, want := scaled.SpawnRateLimit, b.SpawnRateLimit/2; got != want {
+		t.Errorf("spawn rate limit: got %v, want %v", got, want)
+	}
+	if err := scaled.Validate(); err != nil {
+		t.Errorf("scaled balance is invalid: %v", err)
+	}
+	if got := b.Shop["flare"].Price; got != 10 {
+		t.Errorf("original flare price changed to %v", got)
+	}
+}
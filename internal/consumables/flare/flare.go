@@ -1,9 +1,8 @@
 package flare
 
 import (
-	"math/rand"
-
 	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
 )
 
 const (
@@ -22,16 +21,16 @@ var (
 )
 
 type Flare struct {
-	ID     int
-	Pos    rl.Vector2
-	Radius float32
+	ecs.Entity
+	ecs.Transform
+	ecs.Light
 }
 
 func FromPos(pos rl.Vector2) *Flare {
 	return &Flare{
-		ID:     rand.Int(),
-		Pos:    pos,
-		Radius: initialRadius,
+		Entity:    ecs.NewEntity(),
+		Transform: ecs.Transform{Pos: pos, PrevPos: pos},
+		Light:     ecs.Light{Radius: initialRadius, DimSpd: DimSpd},
 	}
 }
 
@@ -42,14 +41,25 @@ func (f *Flare) Draw() {
 	)
 }
 
-func (f *Flare) Dim() {
-	f.Radius *= DimSpd
+// ProgressTime dims the flare, flares dim every frame regardless of frame time
+func (f *Flare) ProgressTime(_ float32) {
+	f.Dim()
+}
+
+// Drain dims the flare from outside, on top of usual dimming.
+// Like DimSpd, factor is applied once per frame.
+func (f *Flare) Drain(factor float32) {
+	f.Radius *= factor
 }
 
 func (f *Flare) WentOut() bool {
 	return f.Radius < wentOutRadius
 }
 
+func (f *Flare) Expired() bool {
+	return f.WentOut()
+}
+
 func (f *Flare) Boundaries() rl.Rectangle {
 	return rl.Rectangle{
 		X:      f.Pos.X - f.Radius,
@@ -58,7 +68,3 @@ func (f *Flare) Boundaries() rl.Rectangle {
 		Height: f.Radius * 2,
 	}
 }
-
-func (f *Flare) GetPos() rl.Vector2 {
-	return f.Pos
-}
@@ -1,9 +1,10 @@
 package grenade
 
 import (
-	"math/rand"
-
 	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
+	"github.com/pechorka/illuminate-game-jam/internal/effects"
 )
 
 var (
@@ -15,22 +16,34 @@ var (
 
 const (
 	duration float32 = 1 // seconds
-	damage   float32 = 100
 )
 
+// Grenade applies effects to everyone in its area every frame while it's active
 type Grenade struct {
-	ID     int
-	Pos    rl.Vector2
-	Damage float32
+	ecs.Entity
+	ecs.Transform
+	ecs.Lifetime
+
+	Effects []effects.Effect
+}
 
-	activeFor float32
+func FromPos(pos rl.Vector2, stats []balance.Effect) *Grenade {
+	g := &Grenade{
+		Entity:    ecs.NewEntity(),
+		Transform: ecs.Transform{Pos: pos, PrevPos: pos},
+		Lifetime:  ecs.Lifetime{Duration: duration},
+	}
+	for _, stat := range stats {
+		g.Effects = append(g.Effects, effects.FromBalance(stat, g.ID))
+	}
+	return g
 }
 
-func FromPos(pos rl.Vector2) *Grenade {
-	return &Grenade{
-		ID:     rand.Int(),
-		Pos:    pos,
-		Damage: damage,
+// ApplyTo applies grenade effects, grenade is the source of them,
+// so being in the area for many frames refreshes effects instead of stacking them
+func (f *Grenade) ApplyTo(status *effects.Status) {
+	for _, e := range f.Effects {
+		status.Apply(e)
 	}
 }
 
@@ -41,12 +54,8 @@ func (f *Grenade) Draw() {
 	)
 }
 
-func (f *Grenade) ProgressTime(dt float32) {
-	f.activeFor += dt
-}
-
 func (f *Grenade) Active() bool {
-	return f.activeFor < duration
+	return !f.Expired()
 }
 
 func (f *Grenade) Boundaries() rl.Rectangle {
@@ -57,7 +66,3 @@ func (f *Grenade) Boundaries() rl.Rectangle {
 		Height: initialDiameter,
 	}
 }
-
-func (f *Grenade) GetPos() rl.Vector2 {
-	return f.Pos
-}
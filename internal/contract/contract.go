@@ -0,0 +1,128 @@
+// Package contract contains timed modifiers that make the run harder for a while,
+// but pay cash and score once they are over.
+package contract
+
+import (
+	"fmt"
+
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+type Kind int
+
+const (
+	Frenzy   Kind = iota + 1 // enemies spawn more often
+	Blackout                 // flares can't be used
+	Elites                   // every spawned enemy is elite
+)
+
+// Kinds in the order they are shown in shop
+var Kinds = []Kind{Frenzy, Blackout, Elites}
+
+func (k Kind) String() string {
+	switch k {
+	case Frenzy:
+		return "Frenzy"
+	case Blackout:
+		return "Blackout"
+	case Elites:
+		return "Elite wave"
+	}
+	return "unknown"
+}
+
+// Stats of contract of given kind
+func (k Kind) Stats(stats balance.Contracts) balance.Contract {
+	switch k {
+	case Frenzy:
+		return stats.Frenzy
+	case Blackout:
+		return stats.Blackout
+	case Elites:
+		return stats.Elites
+	}
+	return balance.Contract{}
+}
+
+// Description tells what contract does and what it pays
+func (k Kind) Description(stats balance.Contracts) string {
+	var what string
+	switch k {
+	case Frenzy:
+		what = fmt.Sprintf("Enemies spawn %vx as often", stats.FrenzySpawns)
+	case Blackout:
+		what = "No flares"
+	case Elites:
+		what = "Every enemy is elite"
+	}
+	s := k.Stats(stats)
+	return fmt.Sprintf("%s for %ds, pays %d$ and %d score", what, int(s.Duration), s.Cash, s.Score)
+}
+
+type Contract struct {
+	Kind  Kind
+	Left  float32 // seconds until contract is completed
+	Stats balance.Contract
+}
+
+func New(kind Kind, stats balance.Contracts) *Contract {
+	s := kind.Stats(stats)
+	return &Contract{Kind: kind, Left: s.Duration, Stats: s}
+}
+
+func (c *Contract) ProgressTime(dt float32) {
+	c.Left = max(0, c.Left-dt)
+}
+
+func (c *Contract) Completed() bool {
+	return c.Left <= 0
+}
+
+// Status is contract with its timer shown in header
+func (c *Contract) Status() string {
+	return fmt.Sprintf("%s %ds", c.Kind, int(c.Left+0.999))
+}
+
+// Active are contracts that are going on, at most one of each kind.
+// Zero value has no contracts.
+type Active struct {
+	contracts []*Contract
+}
+
+// Sign starts contract, false if contract of this kind is already going on
+func (a *Active) Sign(kind Kind, stats balance.Contracts) bool {
+	if a.Has(kind) {
+		return false
+	}
+	a.contracts = append(a.contracts, New(kind, stats))
+	return true
+}
+
+func (a *Active) Has(kind Kind) bool {
+	for _, c := range a.contracts {
+		if c.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Active) List() []*Contract {
+	return a.contracts
+}
+
+// ProgressTime returns contracts that got completed, they are no longer active
+func (a *Active) ProgressTime(dt float32) []*Contract {
+	var completed []*Contract
+	active := a.contracts[:0]
+	for _, c := range a.contracts {
+		c.ProgressTime(dt)
+		if c.Completed() {
+			completed = append(completed, c)
+			continue
+		}
+		active = append(active, c)
+	}
+	a.contracts = active
+	return completed
+}
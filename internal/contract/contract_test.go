@@ -0,0 +1,56 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+var testStats = balance.Contracts{
+	Frenzy:       balance.Contract{Duration: 60, Cash: 150, Score: 300},
+	FrenzySpawns: 2,
+	Blackout:     balance.Contract{Duration: 30, Cash: 100, Score: 200},
+	Elites:       balance.Contract{Duration: 30, Cash: 200, Score: 400},
+}
+
+func TestActive(t *testing.T) {
+	var a Active
+
+	if !a.Sign(Frenzy, testStats) || !a.Sign(Blackout, testStats) {
+		t.Fatal("contracts weren't signed")
+	}
+	if a.Sign(Frenzy, testStats) {
+		t.Errorf("signed the same contract twice")
+	}
+	if !a.Has(Frenzy) || a.Has(Elites) {
+		t.Errorf("got frenzy %v and elites %v, want only frenzy", a.Has(Frenzy), a.Has(Elites))
+	}
+
+	if completed := a.ProgressTime(29.5); len(completed) != 0 {
+		t.Fatalf("got %d contracts completed early", len(completed))
+	}
+	if got := a.List()[1].Status(); got != "Blackout 1s" {
+		t.Errorf("got status %q", got)
+	}
+
+	completed := a.ProgressTime(1)
+	if len(completed) != 1 || completed[0].Kind != Blackout {
+		t.Fatalf("got %d completed contracts, want blackout", len(completed))
+	}
+	if completed[0].Stats != testStats.Blackout {
+		t.Errorf("got stats %v, want blackout stats", completed[0].Stats)
+	}
+	if a.Has(Blackout) || !a.Has(Frenzy) {
+		t.Errorf("completed contract is still active")
+	}
+	if !a.Sign(Blackout, testStats) {
+		t.Errorf("completed contract can't be signed again")
+	}
+}
+
+func TestDescription(t *testing.T) {
+	want := "Enemies spawn 2x as often for 60s, pays 150$ and 300 score"
+	if got := Frenzy.Description(testStats); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
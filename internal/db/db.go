@@ -25,10 +25,12 @@ var (
 )
 
 type Highscore struct {
-	Name    string
-	Time    float32
-	Score   int
-	Victory bool
+	Name       string
+	Time       float32
+	Score      int
+	Victory    bool
+	Difficulty string // empty in records saved before difficulties were added
+	Objective  string // empty in records saved before objectives were added
 }
 
 var ErrNotFound = errors.New("not found")
@@ -0,0 +1,186 @@
+// Package director paces enemy spawns in the style of Left 4 Dead AI director:
+// pressure builds up until soldiers are stressed, peaks for a while,
+// then relaxes so players can recover before the next build up.
+package director
+
+import (
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+type Phase int
+
+const (
+	BuildUp Phase = iota + 1 // spawns get more frequent
+	Peak                     // spawns are as frequent as difficulty allows
+	Relax                    // spawns are rare
+)
+
+func (p Phase) String() string {
+	switch p {
+	case BuildUp:
+		return "build up"
+	case Peak:
+		return "peak"
+	case Relax:
+		return "relax"
+	}
+	return "unknown"
+}
+
+// Snapshot is what director knows about the run when it's updated
+type Snapshot struct {
+	Time          float32 // game time
+	SoldierHealth float32 // part of health soldiers have left, lost soldiers count as empty, 0 to 1
+	FlareCoverage float32 // part of arena lit by flares, 0 to 1
+
+	// difficulty bounds spawn interval has to stay within
+	BaseInterval float32 // seconds between spawns without director
+	MinInterval  float32 // seconds between spawns at the most
+}
+
+// Decision is recorded every time director changes phase
+type Decision struct {
+	Time     float32
+	Phase    Phase // phase director switched to
+	Reason   string
+	Stress   float32
+	KillRate float32 // kills per second
+	Interval float32 // seconds between spawns at the moment of decision
+}
+
+type Director struct {
+	stats balance.Director
+
+	phase     Phase
+	phaseTime float32 // seconds in current phase
+	stress    float32
+	killRate  float32
+	kills     int // since the last update
+	interval  float32
+
+	decisions []Decision
+}
+
+// New director starts in build up
+func New(stats balance.Director) *Director {
+	return &Director{stats: stats, phase: BuildUp}
+}
+
+// Rebalance changes stats, current phase goes on with new durations
+func (d *Director) Rebalance(stats balance.Director) {
+	d.stats = stats
+}
+
+func (d *Director) Phase() Phase {
+	return d.phase
+}
+
+// Stress is how hard soldiers are pressed, 0 to 1
+func (d *Director) Stress() float32 {
+	return d.stress
+}
+
+// KillRate is kills per second averaged over stats.KillWindow
+func (d *Director) KillRate() float32 {
+	return d.killRate
+}
+
+// Decisions returns every phase change since the start of the run
+func (d *Director) Decisions() []Decision {
+	return d.decisions
+}
+
+// ZoneWeights returns how likely enemies come from every kind of spawn zone in current phase
+func (d *Director) ZoneWeights() balance.ZoneWeights {
+	switch d.phase {
+	case Peak:
+		return d.stats.Zones.Peak
+	case Relax:
+		return d.stats.Zones.Relax
+	}
+	return d.stats.Zones.BuildUp
+}
+
+// Killed counts enemy killed by soldiers towards kill rate
+func (d *Director) Killed() {
+	d.kills++
+}
+
+// Update moves director dt seconds forward and returns seconds between spawns
+func (d *Director) Update(dt float32, s Snapshot) float32 {
+	if window := d.stats.KillWindow; window > 0 {
+		d.killRate = d.killRate*max(0, 1-dt/window) + float32(d.kills)/window
+	}
+	d.kills = 0
+
+	d.phaseTime += dt
+	d.stress = d.measureStress(s)
+
+	switch d.phase {
+	case BuildUp:
+		if d.stress >= d.stats.PeakStress {
+			d.switchTo(Peak, "soldiers are stressed", s)
+		} else if d.phaseTime >= d.stats.BuildUp {
+			d.switchTo(Peak, "build up is over", s)
+		}
+	case Peak:
+		if d.phaseTime >= d.stats.Peak {
+			d.switchTo(Relax, "peak is over", s)
+		}
+	case Relax:
+		if d.phaseTime >= d.stats.Relax && d.stress <= d.stats.RelaxStress {
+			d.switchTo(BuildUp, "soldiers calmed down", s)
+		} else if d.phaseTime >= 2*d.stats.Relax {
+			// soldiers don't heal, so badly hurt ones can stay stressed forever
+			d.switchTo(BuildUp, "relax is over", s)
+		}
+	}
+
+	d.interval = d.intervalFor(s)
+	return d.interval
+}
+
+// measureStress weights lost health and darkness against how well soldiers keep up with spawns
+func (d *Director) measureStress(s Snapshot) float32 {
+	w := d.stats.Stress
+	total := w.Health + w.Darkness
+	if total == 0 {
+		return 0
+	}
+	keepUp := float32(0)
+	if d.interval > 0 {
+		keepUp = min(1, d.killRate*d.interval)
+	}
+	stress := w.Health*(1-s.SoldierHealth) + w.Darkness*(1-s.FlareCoverage) - w.Kills*keepUp
+	return min(1, max(0, stress/total))
+}
+
+func (d *Director) intervalFor(s Snapshot) float32 {
+	var multiplier float32
+	switch d.phase {
+	case BuildUp:
+		progress := float32(1)
+		if d.stats.BuildUp > 0 {
+			progress = min(1, d.phaseTime/d.stats.BuildUp)
+		}
+		multiplier = 1 + (d.stats.PeakRate-1)*progress
+	case Peak:
+		multiplier = d.stats.PeakRate
+	case Relax:
+		multiplier = d.stats.RelaxRate
+	}
+	return max(s.BaseInterval*multiplier, s.MinInterval)
+}
+
+func (d *Director) switchTo(phase Phase, reason string, s Snapshot) {
+	d.phase = phase
+	d.phaseTime = 0
+	d.decisions = append(d.decisions, Decision{
+		Time:     s.Time,
+		Phase:    phase,
+		Reason:   reason,
+		Stress:   d.stress,
+		KillRate: d.killRate,
+		Interval: d.intervalFor(s),
+	})
+}
@@ -0,0 +1,148 @@
+package director
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+var testStats = balance.Director{
+	BuildUp:     40,
+	Peak:        10,
+	Relax:       20,
+	PeakStress:  0.6,
+	RelaxStress: 0.3,
+	PeakRate:    0.5,
+	RelaxRate:   3,
+	KillWindow:  10,
+	Stress:      balance.Stress{Health: 0.5, Darkness: 0.5, Kills: 0.5},
+	Zones: balance.Zones{
+		BuildUp: balance.ZoneWeights{Edge: 1},
+		Peak:    balance.ZoneWeights{Nest: 1},
+		Relax:   balance.ZoneWeights{Corner: 1},
+	},
+}
+
+// calm soldiers are healthy and half of arena is lit
+var calm = Snapshot{SoldierHealth: 1, FlareCoverage: 0.5, BaseInterval: 2, MinInterval: 0.5}
+
+func approx(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+func TestPhases(t *testing.T) {
+	t.Run("build up gets to peak and relaxes", func(t *testing.T) {
+		d := New(testStats)
+
+		if got := d.Update(20, calm); !approx(got, 1.5) {
+			t.Errorf("halfway through build up: got interval %v, want 1.5", got)
+		}
+		d.Update(20, calm)
+		if d.Phase() != Peak {
+			t.Fatalf("got %s after build up, want peak", d.Phase())
+		}
+		if got := d.ZoneWeights(); got != testStats.Zones.Peak {
+			t.Errorf("at peak: got zone weights %+v, want %+v", got, testStats.Zones.Peak)
+		}
+		if got := d.Update(5, calm); got != 1 {
+			t.Errorf("at peak: got interval %v, want 1", got)
+		}
+		d.Update(5, calm)
+		if d.Phase() != Relax {
+			t.Fatalf("got %s after peak, want relax", d.Phase())
+		}
+		if got := d.Update(1, calm); got != 6 {
+			t.Errorf("during relax: got interval %v, want 6", got)
+		}
+		d.Update(19, calm)
+		if d.Phase() != BuildUp {
+			t.Fatalf("got %s after relax, want build up", d.Phase())
+		}
+
+		reasons := []string{"build up is over", "peak is over", "soldiers calmed down"}
+		if len(d.Decisions()) != len(reasons) {
+			t.Fatalf("got %d decisions, want %d", len(d.Decisions()), len(reasons))
+		}
+		for i, reason := range reasons {
+			if got := d.Decisions()[i].Reason; got != reason {
+				t.Errorf("decision %d: got %q, want %q", i, got, reason)
+			}
+		}
+	})
+
+	t.Run("stressed soldiers cut build up short", func(t *testing.T) {
+		d := New(testStats)
+		hurt := calm
+		hurt.SoldierHealth = 0.2
+		hurt.Time = 5
+
+		d.Update(5, hurt)
+
+		if d.Phase() != Peak {
+			t.Fatalf("got %s, want peak", d.Phase())
+		}
+		decision := d.Decisions()[0]
+		if decision.Time != 5 || decision.Reason != "soldiers are stressed" || !approx(decision.Stress, 0.65) {
+			t.Errorf("got decision %+v", decision)
+		}
+	})
+
+	t.Run("relax lasts while soldiers are stressed", func(t *testing.T) {
+		d := New(testStats)
+		hurt := calm
+		hurt.SoldierHealth = 0.2
+		d.Update(0, hurt)
+		d.Update(testStats.Peak, hurt)
+
+		d.Update(testStats.Relax, hurt)
+		if d.Phase() != Relax {
+			t.Fatalf("got %s, want relax to go on", d.Phase())
+		}
+		d.Update(testStats.Relax, hurt)
+		if d.Phase() != BuildUp {
+			t.Fatalf("got %s, want relax to end after twice its duration", d.Phase())
+		}
+	})
+
+	t.Run("interval stays within difficulty bounds", func(t *testing.T) {
+		d := New(testStats)
+		late := calm
+		late.BaseInterval = 0.6
+
+		d.Update(testStats.BuildUp, late)
+		if got := d.Update(0, late); got != late.MinInterval {
+			t.Errorf("got interval %v, want %v", got, late.MinInterval)
+		}
+	})
+}
+
+func TestStress(t *testing.T) {
+	t.Run("darkness and lost health", func(t *testing.T) {
+		d := New(testStats)
+		d.Update(0, Snapshot{SoldierHealth: 0.5, FlareCoverage: 0.5, BaseInterval: 2})
+
+		if got := d.Stress(); !approx(got, 0.5) {
+			t.Errorf("got stress %v, want 0.5", got)
+		}
+	})
+
+	t.Run("keeping up with spawns lowers stress", func(t *testing.T) {
+		d := New(testStats)
+		dark := Snapshot{SoldierHealth: 1, BaseInterval: 2}
+		d.Update(0, dark)
+		before := d.Stress()
+
+		for range 5 {
+			d.Killed()
+		}
+		d.Update(0, dark)
+
+		if got := d.KillRate(); !approx(got, 0.5) {
+			t.Errorf("got kill rate %v, want 0.5", got)
+		}
+		if d.Stress() >= before {
+			t.Errorf("got stress %v, want less than %v", d.Stress(), before)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package ecs
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/pkg/rlutils"
+)
+
+type Transform struct {
+	Pos     rl.Vector2
+	PrevPos rl.Vector2 // position before the last move
+}
+
+func (t *Transform) GetTransform() *Transform {
+	return t
+}
+
+func (t *Transform) GetPos() rl.Vector2 {
+	return t.Pos
+}
+
+func (t *Transform) UpdatePosition(pos rl.Vector2) {
+	t.PrevPos = t.Pos
+	t.Pos = pos
+}
+
+// Velocity moves entity by Speed pixels per frame in Dir direction
+type Velocity struct {
+	Dir   rl.Vector2 // normalized
+	Speed float32
+}
+
+func (v *Velocity) GetVelocity() *Velocity {
+	return v
+}
+
+// Route is a path around obstacles, entity walks to waypoints one by one
+type Route struct {
+	Waypoints []rl.Vector2
+	Goal      rl.Vector2 // position route was built to
+}
+
+func (r *Route) GetRoute() *Route {
+	return r
+}
+
+// Next returns waypoint entity should walk to
+func (r *Route) Next() (rl.Vector2, bool) {
+	if len(r.Waypoints) == 0 {
+		return rl.Vector2{}, false
+	}
+	return r.Waypoints[0], true
+}
+
+// Advance drops the current waypoint once it's reached
+func (r *Route) Advance() {
+	if len(r.Waypoints) > 0 {
+		r.Waypoints = r.Waypoints[1:]
+	}
+}
+
+func (r *Route) Clear() {
+	r.Waypoints = nil
+}
+
+type Health struct {
+	HP    float32
+	MaxHP float32
+}
+
+func NewHealth(hp float32) Health {
+	return Health{HP: hp, MaxHP: hp}
+}
+
+func (h *Health) IsDead() bool {
+	return h.HP <= 0
+}
+
+func (h *Health) TakeDamage(damage float32) {
+	h.HP -= damage
+}
+
+// Heal restores HP, but not above MaxHP
+func (h *Health) Heal(amount float32) {
+	h.HP = min(h.HP+amount, h.MaxHP)
+}
+
+type Sprite struct {
+	Texture rl.Texture2D
+}
+
+func (s *Sprite) GetSprite() *Sprite {
+	return s
+}
+
+func (s *Sprite) DrawAt(pos rl.Vector2) {
+	s.DrawTintedAt(pos, rl.White)
+}
+
+func (s *Sprite) DrawTintedAt(pos rl.Vector2, tint rl.Color) {
+	rl.DrawTexture(s.Texture, int32(pos.X), int32(pos.Y), tint)
+}
+
+func (s *Sprite) BoundariesAt(pos rl.Vector2) rl.Rectangle {
+	return rlutils.TextureBoundaries(s.Texture, pos)
+}
+
+// Light shrinks by DimSpd every frame
+type Light struct {
+	Radius float32
+	DimSpd float32
+}
+
+func (l *Light) Dim() {
+	l.Radius *= l.DimSpd
+}
+
+type Damage struct {
+	Amount float32
+}
+
+func (d *Damage) DealDamage() float32 {
+	return d.Amount
+}
+
+type Team int
+
+const (
+	TeamNeutral Team = iota
+	TeamSoldiers
+	TeamEnemies
+)
+
+func (t Team) GetTeam() Team {
+	return t
+}
+
+// Lifetime expires after Duration seconds, or when Expire is called.
+// Zero Duration means entity lives until expired explicitly.
+type Lifetime struct {
+	Age      float32
+	Duration float32
+
+	expired bool
+}
+
+func (l *Lifetime) ProgressTime(dt float32) {
+	l.Age += dt
+}
+
+func (l *Lifetime) Expire() {
+	l.expired = true
+}
+
+func (l *Lifetime) Expired() bool {
+	return l.expired || (l.Duration > 0 && l.Age >= l.Duration)
+}
@@ -0,0 +1,22 @@
+// Package ecs contains components and systems shared by all game entities.
+//
+// Entities are plain structs that embed components, so component fields and
+// methods are promoted to the entity. A slice of entities of the same type
+// acts as an archetype storage, and systems are generic functions over such
+// slices that require only the components they work with.
+package ecs
+
+import "math/rand"
+
+// Entity identifies everything that lives in the arena
+type Entity struct {
+	ID int
+}
+
+func NewEntity() Entity {
+	return Entity{ID: rand.Int()}
+}
+
+func (e *Entity) GetID() int {
+	return e.ID
+}
@@ -0,0 +1,113 @@
+package ecs
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/pkg/data_structures/quadtree"
+)
+
+type testEntity struct {
+	Entity
+	Transform
+	Velocity
+	Lifetime
+}
+
+func (e *testEntity) Boundaries() rl.Rectangle {
+	return rl.Rectangle{X: e.Pos.X, Y: e.Pos.Y, Width: 1, Height: 1}
+}
+
+func TestMove(t *testing.T) {
+	e := &testEntity{
+		Transform: Transform{Pos: rl.Vector2{X: 10, Y: 10}},
+		Velocity:  Velocity{Dir: rl.Vector2{X: 1}, Speed: 5},
+	}
+
+	Move([]*testEntity{e})
+
+	want := rl.Vector2{X: 15, Y: 10}
+	if e.Pos != want {
+		t.Errorf("got pos %v, want %v", e.Pos, want)
+	}
+	wantPrev := rl.Vector2{X: 10, Y: 10}
+	if e.PrevPos != wantPrev {
+		t.Errorf("got prev pos %v, want %v", e.PrevPos, wantPrev)
+	}
+}
+
+func TestAge(t *testing.T) {
+	t.Run("removes expired keeping order", func(t *testing.T) {
+		items := []*testEntity{
+			{Entity: Entity{ID: 1}, Lifetime: Lifetime{Duration: 1}},
+			{Entity: Entity{ID: 2}, Lifetime: Lifetime{Duration: 3}},
+			{Entity: Entity{ID: 3}},
+			{Entity: Entity{ID: 4}, Lifetime: Lifetime{Duration: 3}},
+		}
+		items[2].Expire()
+
+		items = Age(items, 2)
+
+		if len(items) != 2 {
+			t.Fatalf("got %d items, want 2", len(items))
+		}
+		if items[0].ID != 2 || items[1].ID != 4 {
+			t.Errorf("got ids %v and %v, want 2 and 4", items[0].ID, items[1].ID)
+		}
+	})
+
+	t.Run("zero duration lives until expired", func(t *testing.T) {
+		items := []*testEntity{{}}
+
+		items = Age(items, 1000)
+
+		if len(items) != 1 {
+			t.Errorf("got %d items, want 1", len(items))
+		}
+	})
+}
+
+func TestCollide(t *testing.T) {
+	qt := quadtree.NewQuadtree(rl.Rectangle{Width: 100, Height: 100}, 4)
+	items := []*testEntity{
+		{Entity: Entity{ID: 1}, Transform: Transform{Pos: rl.Vector2{X: 10, Y: 10}}},
+		{Entity: Entity{ID: 2}, Transform: Transform{Pos: rl.Vector2{X: 90, Y: 90}}},
+	}
+
+	Collide(qt, items)
+
+	got := qt.Query(rl.Rectangle{X: 5, Y: 5, Width: 10, Height: 10})
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("got %v, want only entity 1", got)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	h := NewHealth(10)
+	h.TakeDamage(4)
+	if h.IsDead() {
+		t.Errorf("dead after 4 damage out of 10")
+	}
+	h.TakeDamage(6)
+	if !h.IsDead() {
+		t.Errorf("alive after 10 damage out of 10")
+	}
+	if h.MaxHP != 10 {
+		t.Errorf("got max hp %v, want 10", h.MaxHP)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	r := Route{Waypoints: []rl.Vector2{{X: 1}, {X: 2}}}
+
+	next, ok := r.Next()
+	if !ok || next != (rl.Vector2{X: 1}) {
+		t.Errorf("got next %v, %v, want {1 0}, true", next, ok)
+	}
+	r.Advance()
+	r.Advance()
+	r.Advance()
+	if _, ok := r.Next(); ok {
+		t.Errorf("got next waypoint after reaching all of them")
+	}
+}
@@ -0,0 +1,63 @@
+package ecs
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/pkg/data_structures/quadtree"
+)
+
+type Mover interface {
+	GetTransform() *Transform
+	GetVelocity() *Velocity
+}
+
+// Move is a movement system, it moves entities along their velocity
+func Move[T Mover](items []T) {
+	for _, item := range items {
+		v := item.GetVelocity()
+		t := item.GetTransform()
+		t.UpdatePosition(rl.Vector2Add(t.Pos, rl.Vector2Scale(v.Dir, v.Speed)))
+	}
+}
+
+type Collider interface {
+	GetID() int
+	Boundaries() rl.Rectangle
+}
+
+// Collide is a collision system, it makes entities visible to quadtree queries
+func Collide[T Collider](qt *quadtree.Quadtree, items []T) {
+	for _, item := range items {
+		qt.Insert(item.GetID(), item.Boundaries(), item)
+	}
+}
+
+type Mortal interface {
+	ProgressTime(dt float32)
+	Expired() bool
+}
+
+// Age is a lifetime system, it progresses time of entities
+// and removes expired ones keeping the order of the rest
+func Age[T Mortal](items []T, dt float32) []T {
+	alive := items[:0]
+	for _, item := range items {
+		item.ProgressTime(dt)
+		if item.Expired() {
+			continue
+		}
+		alive = append(alive, item)
+	}
+	clear(items[len(alive):])
+	return alive
+}
+
+type Drawable interface {
+	Draw()
+}
+
+// Render is a render system
+func Render[T Drawable](items []T) {
+	for _, item := range items {
+		item.Draw()
+	}
+}
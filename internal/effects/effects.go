@@ -0,0 +1,155 @@
+// Package effects contains timed status effects that can be applied to enemies and soldiers.
+package effects
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+type Kind int
+
+const (
+	Burn  Kind = iota + 1 // deals Strength damage per second
+	Slow                  // takes Strength part of speed
+	Stun                  // stops any action
+	Blind                 // takes Strength part of shooting range
+)
+
+var kindsByName = map[string]Kind{
+	"burn":  Burn,
+	"slow":  Slow,
+	"stun":  Stun,
+	"blind": Blind,
+}
+
+// MaxBurnStacks is how many sources can burn the same entity at once
+const MaxBurnStacks = 3
+
+var iconColors = map[Kind]rl.Color{
+	Burn:  rl.Orange,
+	Slow:  rl.SkyBlue,
+	Stun:  rl.Yellow,
+	Blind: rl.LightGray,
+}
+
+const iconSize = 6
+
+type Effect struct {
+	Kind     Kind
+	Source   int // ID of entity that applied effect
+	Strength float32
+	Left     float32 // seconds
+}
+
+// FromBalance creates effect applied by source
+func FromBalance(b balance.Effect, source int) Effect {
+	return Effect{Kind: kindsByName[b.Kind], Source: source, Strength: b.Strength, Left: b.Duration}
+}
+
+// Status is a component that keeps effects applied to entity.
+//
+// Effects of the same kind from the same source don't stack, the new one refreshes the old one.
+// Burns from different sources stack up to MaxBurnStacks, the one closest to expiring is replaced.
+// Of all slows and blinds only the strongest one counts.
+type Status struct {
+	effects []Effect
+}
+
+func (s *Status) GetStatus() *Status {
+	return s
+}
+
+func (s *Status) Apply(e Effect) {
+	sameKind := 0
+	weakest := -1
+	for i, active := range s.effects {
+		if active.Kind != e.Kind {
+			continue
+		}
+		if active.Source == e.Source {
+			s.effects[i].Strength = e.Strength
+			s.effects[i].Left = max(active.Left, e.Left)
+			return
+		}
+		sameKind++
+		if weakest == -1 || active.Left < s.effects[weakest].Left {
+			weakest = i
+		}
+	}
+	if e.Kind == Burn && sameKind >= MaxBurnStacks {
+		s.effects[weakest] = e
+		return
+	}
+	s.effects = append(s.effects, e)
+}
+
+// ProgressEffects counts effects down, removes expired ones
+// and returns damage burns dealt during dt
+func (s *Status) ProgressEffects(dt float32) float32 {
+	var damage float32
+	active := s.effects[:0]
+	for _, e := range s.effects {
+		step := min(dt, e.Left)
+		if e.Kind == Burn {
+			damage += e.Strength * step
+		}
+		e.Left -= step
+		if e.Left > 0 {
+			active = append(active, e)
+		}
+	}
+	s.effects = active
+	return damage
+}
+
+func (s *Status) Effects() []Effect {
+	return s.effects
+}
+
+func (s *Status) Stunned() bool {
+	return s.strongest(Stun) != nil
+}
+
+// SpeedMultiplier is what speed is multiplied by because of slows and stuns
+func (s *Status) SpeedMultiplier() float32 {
+	if s.Stunned() {
+		return 0
+	}
+	return s.multiplier(Slow)
+}
+
+// RangeMultiplier is what shooting range is multiplied by because of blinds
+func (s *Status) RangeMultiplier() float32 {
+	return s.multiplier(Blind)
+}
+
+func (s *Status) multiplier(kind Kind) float32 {
+	e := s.strongest(kind)
+	if e == nil {
+		return 1
+	}
+	return max(0, 1-e.Strength)
+}
+
+func (s *Status) strongest(kind Kind) *Effect {
+	var strongest *Effect
+	for i, e := range s.effects {
+		if e.Kind == kind && (strongest == nil || e.Strength > strongest.Strength) {
+			strongest = &s.effects[i]
+		}
+	}
+	return strongest
+}
+
+// DrawEffects draws an icon for every kind of active effect in a row above boundaries
+func (s *Status) DrawEffects(boundaries rl.Rectangle) {
+	x := boundaries.X
+	y := boundaries.Y - iconSize - 2
+	for _, kind := range []Kind{Burn, Slow, Stun, Blind} {
+		if s.strongest(kind) == nil {
+			continue
+		}
+		rl.DrawRectangleRec(rl.Rectangle{X: x, Y: y, Width: iconSize, Height: iconSize}, iconColors[kind])
+		x += iconSize + 2
+	}
+}
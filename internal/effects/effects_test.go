@@ -0,0 +1,90 @@
+package effects
+
+import (
+	"testing"
+
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+func TestApply(t *testing.T) {
+	t.Run("same source refreshes effect", func(t *testing.T) {
+		var s Status
+		s.Apply(Effect{Kind: Burn, Source: 1, Strength: 10, Left: 1})
+		s.Apply(Effect{Kind: Burn, Source: 1, Strength: 20, Left: 3})
+		s.Apply(Effect{Kind: Burn, Source: 1, Strength: 20, Left: 2})
+
+		if len(s.Effects()) != 1 {
+			t.Fatalf("got %d effects, want 1", len(s.Effects()))
+		}
+		if got := s.Effects()[0]; got.Strength != 20 || got.Left != 3 {
+			t.Errorf("got strength %v for %v seconds, want 20 for 3", got.Strength, got.Left)
+		}
+	})
+
+	t.Run("burns stack up to limit", func(t *testing.T) {
+		var s Status
+		for source := range MaxBurnStacks + 2 {
+			s.Apply(Effect{Kind: Burn, Source: source, Strength: 10, Left: float32(source + 1)})
+		}
+
+		if len(s.Effects()) != MaxBurnStacks {
+			t.Fatalf("got %d burns, want %d", len(s.Effects()), MaxBurnStacks)
+		}
+		if got, want := s.ProgressEffects(0.5), float32(10*0.5*MaxBurnStacks); got != want {
+			t.Errorf("got burn damage %v, want %v", got, want)
+		}
+	})
+
+	t.Run("only strongest slow counts", func(t *testing.T) {
+		var s Status
+		s.Apply(Effect{Kind: Slow, Source: 1, Strength: 0.25, Left: 1})
+		s.Apply(Effect{Kind: Slow, Source: 2, Strength: 0.5, Left: 1})
+
+		if got := s.SpeedMultiplier(); got != 0.5 {
+			t.Errorf("got speed multiplier %v, want 0.5", got)
+		}
+	})
+
+	t.Run("stun stops movement", func(t *testing.T) {
+		var s Status
+		s.Apply(Effect{Kind: Stun, Source: 1, Left: 1})
+
+		if !s.Stunned() {
+			t.Errorf("not stunned")
+		}
+		if got := s.SpeedMultiplier(); got != 0 {
+			t.Errorf("got speed multiplier %v, want 0", got)
+		}
+	})
+
+	t.Run("blind reduces range", func(t *testing.T) {
+		var s Status
+		s.Apply(FromBalance(balance.Effect{Kind: "blind", Strength: 0.3, Duration: 1}, 1))
+
+		if got := s.RangeMultiplier(); got != 0.7 {
+			t.Errorf("got range multiplier %v, want 0.7", got)
+		}
+	})
+}
+
+func TestProgressEffects(t *testing.T) {
+	var s Status
+	s.Apply(Effect{Kind: Burn, Source: 1, Strength: 10, Left: 1})
+	s.Apply(Effect{Kind: Slow, Source: 1, Strength: 0.5, Left: 2})
+
+	// burn deals damage only for time it had left
+	if got := s.ProgressEffects(1.5); got != 10 {
+		t.Errorf("got damage %v, want 10", got)
+	}
+	if len(s.Effects()) != 1 || s.Effects()[0].Kind != Slow {
+		t.Errorf("got effects %v, want only slow", s.Effects())
+	}
+
+	s.ProgressEffects(1)
+	if len(s.Effects()) != 0 {
+		t.Errorf("got effects %v after all expired", s.Effects())
+	}
+	if got := s.SpeedMultiplier(); got != 1 {
+		t.Errorf("got speed multiplier %v, want 1", got)
+	}
+}
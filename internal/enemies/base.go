@@ -0,0 +1,169 @@
+package enemies
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
+	"github.com/pechorka/illuminate-game-jam/internal/effects"
+)
+
+// Enemy is what game loop knows about every enemy type
+type Enemy interface {
+	GetID() int
+	Name() string
+	IsDead() bool
+	Reward() int
+	GetPos() rl.Vector2
+	GetVelocity() *ecs.Velocity
+	GetRoute() *ecs.Route
+	GetStatus() *effects.Status
+	GetElite() *Elite
+	MakeElite(affixes []Affix, time float32, stats balance.Elite)
+	UpdatePosition(rl.Vector2)
+	Draw()
+	DealDamage() float32
+	TakeDamage(float32)
+	Boundaries() rl.Rectangle
+	Rebalance(prev, next balance.Enemy)
+	Steering() balance.Steering
+
+	Hooks
+}
+
+// Hooks are called by game loop, so special enemies can override them to change behaviour.
+// Base implements all of them as no-op for regular enemies.
+type Hooks interface {
+	// OnSpawn is called once enemy is added to the arena
+	OnSpawn()
+	// OnDamaged is called after enemy took damage, even if it died from it
+	OnDamaged(damage float32)
+	// OnHit is called after enemy dealt damage to a soldier it touched
+	OnHit(damage float32)
+	// OnDeath is called once when dead enemy is removed from the arena
+	OnDeath()
+	// CustomMove replaces moving towards target, if ok is false enemy moves as usual.
+	// It's called concurrently for different enemies, so it may only change the enemy itself.
+	CustomMove(target rl.Vector2) (newPos rl.Vector2, ok bool)
+}
+
+// Profile describes enemy type
+type Profile struct {
+	Name    string
+	Texture rl.Texture2D
+	Stats   balance.Enemy
+}
+
+// Base is an enemy that walks towards soldiers, stats are rolled from profile
+// and scaled with game time. Enemy types embed it and override what they need.
+type Base struct {
+	ecs.Entity
+	ecs.Transform
+	ecs.Velocity
+	ecs.Route
+	ecs.Health
+	ecs.Damage
+	ecs.Sprite
+	ecs.Team
+	effects.Status
+	Elite
+
+	name         string
+	initialSpeed float32
+	steering     balance.Steering
+}
+
+func NewBase(pos rl.Vector2, time float32, profile Profile) Base {
+	stats := profile.Stats
+	initialSpeed := ScaledStat(stats.Speed.From, stats.Speed.To, time)
+	initialHealth := ScaledStat(stats.Health.From, stats.Health.To, time)
+	initialDamage := ScaledStat(stats.Damage.From, stats.Damage.To, time)
+	return Base{
+		Entity:    ecs.NewEntity(),
+		Transform: ecs.Transform{Pos: pos, PrevPos: pos},
+		Velocity:  ecs.Velocity{Speed: initialSpeed},
+		Health:    ecs.NewHealth(initialHealth),
+		Damage:    ecs.Damage{Amount: initialDamage},
+		Sprite:    ecs.Sprite{Texture: profile.Texture},
+		Team:      ecs.TeamEnemies,
+
+		name:         profile.Name,
+		initialSpeed: initialSpeed,
+		steering:     stats.Steering,
+	}
+}
+
+// Name is the key of enemy stats in balance file
+func (b *Base) Name() string {
+	return b.name
+}
+
+func (b *Base) Reward() int {
+	return int(float32(Reward(b.MaxHP, b.initialSpeed)) * b.RewardMultiplier())
+}
+
+// TakeDamage takes armour of armoured elites off damage
+func (b *Base) TakeDamage(damage float32) {
+	b.Health.TakeDamage(b.reduce(damage))
+}
+
+// IgnoresFlares is true for flare shielded elites
+func (b *Base) IgnoresFlares() bool {
+	return b.Has(FlareShielded)
+}
+
+// Rebalance rescales stats, so enemy is as strong in next balance as it was in prev one.
+// Health ratio stays the same.
+func (b *Base) Rebalance(prev, next balance.Enemy) {
+	b.Speed = Rescale(b.Speed, prev.Speed, next.Speed)
+	b.initialSpeed = Rescale(b.initialSpeed, prev.Speed, next.Speed)
+	b.HP = Rescale(b.HP, prev.Health, next.Health)
+	b.MaxHP = Rescale(b.MaxHP, prev.Health, next.Health)
+	b.Amount = Rescale(b.Amount, prev.Damage, next.Damage)
+	b.steering = next.Steering
+}
+
+// Steering returns weights of forces that move enemy
+func (b *Base) Steering() balance.Steering {
+	return b.steering
+}
+
+// MoveAway is position one step away from pos, for custom moves that keep distance.
+// Game loop moves enemies with steering forces, so it's not part of Enemy.
+func (b *Base) MoveAway(pos rl.Vector2) rl.Vector2 {
+	dir := rl.Vector2Subtract(b.Pos, pos)
+	dir = rl.Vector2Normalize(dir)
+	dir = rl.Vector2Scale(dir, b.Speed)
+
+	return rl.Vector2Add(b.Pos, dir)
+}
+
+func (b *Base) Draw() {
+	if b.IsElite() {
+		b.DrawTintedAt(b.Pos, eliteTint)
+		b.drawOutlines(b.Boundaries())
+	} else {
+		b.DrawAt(b.Pos)
+	}
+	b.DrawEffects(b.Boundaries())
+}
+
+func (b *Base) Boundaries() rl.Rectangle {
+	return b.BoundariesAt(b.Pos)
+}
+
+func (b *Base) OnSpawn() {}
+
+func (b *Base) OnDamaged(damage float32) {}
+
+// OnHit heals vampiric elites
+func (b *Base) OnHit(damage float32) {
+	if b.Has(Vampiric) {
+		b.Heal(damage * b.vampiric)
+	}
+}
+
+func (b *Base) OnDeath() {}
+
+func (b *Base) CustomMove(target rl.Vector2) (rl.Vector2, bool) {
+	return rl.Vector2{}, false
+}
@@ -0,0 +1,120 @@
+package boss
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
+	"github.com/pechorka/illuminate-game-jam/pkg/rlutils"
+)
+
+// Name of boss, it's stats are in boss section of balance file
+const Name = "boss"
+
+// summonDistance is how far from boss center minions appear
+const summonDistance = 100
+
+// Boss goes through phases as it loses health: summons minions,
+// charges at soldiers and ignores flares for a while
+type Boss struct {
+	enemies.Base
+
+	phases           []balance.BossPhase
+	phase            int // number of phases boss went through
+	rewardMultiplier int
+	chargeSpeed      float32
+
+	charging      float32 // seconds left
+	flareImmunity float32 // seconds left
+
+	newMinion func(pos rl.Vector2) enemies.Enemy
+	summoned  []enemies.Enemy
+}
+
+func FromPos(
+	pos rl.Vector2,
+	texture rl.Texture2D,
+	time float32,
+	stats balance.Boss,
+	newMinion func(pos rl.Vector2) enemies.Enemy,
+) *Boss {
+	return &Boss{
+		Base: enemies.NewBase(pos, time, enemies.Profile{
+			Name:    Name,
+			Texture: texture,
+			Stats:   stats.Stats(),
+		}),
+		phases:           stats.Phases,
+		rewardMultiplier: stats.RewardMultiplier,
+		chargeSpeed:      stats.ChargeSpeed,
+		newMinion:        newMinion,
+	}
+}
+
+func (b *Boss) Reward() int {
+	return b.Base.Reward() * b.rewardMultiplier
+}
+
+// Phase is the number of phases boss went through, zero until first health threshold
+func (b *Boss) Phase() int {
+	return b.phase
+}
+
+// Phases returns health thresholds of all phases
+func (b *Boss) Phases() []balance.BossPhase {
+	return b.phases
+}
+
+func (b *Boss) Charging() bool {
+	return b.charging > 0
+}
+
+func (b *Boss) IgnoresFlares() bool {
+	return b.flareImmunity > 0
+}
+
+// OnDamaged enters every phase which threshold was crossed by the damage
+func (b *Boss) OnDamaged(damage float32) {
+	for b.phase < len(b.phases) && b.HP <= b.MaxHP*b.phases[b.phase].HealthBelow {
+		b.enterPhase(b.phases[b.phase])
+		b.phase++
+	}
+}
+
+func (b *Boss) enterPhase(p balance.BossPhase) {
+	b.charging = max(b.charging, p.Charge)
+	b.flareImmunity = max(b.flareImmunity, p.FlareImmunity)
+
+	center := rlutils.RectangleCenter(b.Boundaries())
+	for i := range p.Summon {
+		angle := 2 * math.Pi * float64(i) / float64(p.Summon)
+		pos := rl.Vector2{
+			X: center.X + summonDistance*float32(math.Cos(angle)),
+			Y: center.Y + summonDistance*float32(math.Sin(angle)),
+		}
+		b.summoned = append(b.summoned, b.newMinion(pos))
+	}
+}
+
+func (b *Boss) Summoned() []enemies.Enemy {
+	summoned := b.summoned
+	b.summoned = nil
+	return summoned
+}
+
+func (b *Boss) ProgressTime(dt float32) {
+	b.charging = max(b.charging-dt, 0)
+	b.flareImmunity = max(b.flareImmunity-dt, 0)
+}
+
+// CustomMove rushes at soldier while charging
+func (b *Boss) CustomMove(target rl.Vector2) (rl.Vector2, bool) {
+	if !b.Charging() {
+		return rl.Vector2{}, false
+	}
+	dir := rl.Vector2Subtract(target, b.Pos)
+	dir = rl.Vector2Normalize(dir)
+	dir = rl.Vector2Scale(dir, b.Speed*b.chargeSpeed)
+	return rl.Vector2Add(b.Pos, dir), true
+}
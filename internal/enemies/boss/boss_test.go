@@ -0,0 +1,102 @@
+package boss
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
+)
+
+var testStats = balance.Boss{
+	Speed:            balance.Range{From: 1, To: 1},
+	Health:           balance.Range{From: 100, To: 100},
+	Damage:           balance.Range{From: 10, To: 10},
+	RewardMultiplier: 10,
+	ChargeSpeed:      4,
+	Phases: []balance.BossPhase{
+		{HealthBelow: 0.75, Summon: 4},
+		{HealthBelow: 0.5, Charge: 3},
+		{HealthBelow: 0.25, FlareImmunity: 5},
+	},
+}
+
+func newTestBoss() *Boss {
+	return FromPos(rl.Vector2{X: 500, Y: 500}, rl.Texture2D{Width: 20, Height: 20}, 0, testStats,
+		func(pos rl.Vector2) enemies.Enemy {
+			return &enemies.Base{}
+		})
+}
+
+func damage(b *Boss, amount float32) {
+	b.TakeDamage(amount)
+	b.OnDamaged(amount)
+}
+
+func TestPhases(t *testing.T) {
+	t.Run("summons minions around itself", func(t *testing.T) {
+		b := newTestBoss()
+
+		damage(b, 20)
+		if b.Phase() != 0 || len(b.Summoned()) != 0 {
+			t.Fatalf("phase started before health threshold")
+		}
+
+		damage(b, 5)
+		if b.Phase() != 1 {
+			t.Errorf("got phase %d, want 1", b.Phase())
+		}
+		if got := len(b.Summoned()); got != 4 {
+			t.Errorf("got %d minions, want 4", got)
+		}
+		if got := len(b.Summoned()); got != 0 {
+			t.Errorf("got %d minions on second call, want 0", got)
+		}
+	})
+
+	t.Run("charges", func(t *testing.T) {
+		b := newTestBoss()
+		target := rl.Vector2{X: 1000, Y: 500}
+		if _, ok := b.CustomMove(target); ok {
+			t.Errorf("charging before the phase")
+		}
+
+		damage(b, 50)
+		got, ok := b.CustomMove(target)
+		if !ok {
+			t.Fatalf("not charging after the phase")
+		}
+		if want := (rl.Vector2{X: 504, Y: 500}); got != want {
+			t.Errorf("got pos %v, want %v", got, want)
+		}
+
+		b.ProgressTime(3)
+		if _, ok := b.CustomMove(target); ok {
+			t.Errorf("still charging after charge time")
+		}
+	})
+
+	t.Run("big hit enters all crossed phases", func(t *testing.T) {
+		b := newTestBoss()
+
+		damage(b, 80)
+
+		if b.Phase() != 3 {
+			t.Errorf("got phase %d, want 3", b.Phase())
+		}
+		if len(b.Summoned()) != 4 || !b.Charging() || !b.IgnoresFlares() {
+			t.Errorf("not all phases took effect")
+		}
+		b.ProgressTime(5)
+		if b.IgnoresFlares() {
+			t.Errorf("still ignores flares after immunity time")
+		}
+	})
+}
+
+func TestReward(t *testing.T) {
+	b := newTestBoss()
+	if got, want := b.Reward(), enemies.Reward(100, 1)*10; got != want {
+		t.Errorf("got reward %d, want %d", got, want)
+	}
+}
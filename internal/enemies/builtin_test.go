@@ -0,0 +1,106 @@
+package enemies_test
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/basic"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/fast"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/tank"
+	"github.com/pechorka/illuminate-game-jam/internal/steering"
+)
+
+var testTexture = rl.Texture2D{Width: 16, Height: 16}
+
+var testStats = balance.Enemy{
+	Speed:    balance.Range{From: 2, To: 2},
+	Health:   balance.Range{From: 100, To: 100},
+	Damage:   balance.Range{From: 5, To: 5},
+	Steering: balance.Steering{Seek: 1, Flee: 3, Separation: 1},
+}
+
+func TestBuiltinEnemies(t *testing.T) {
+	types := []struct {
+		name string
+		new  func(pos rl.Vector2, texture rl.Texture2D, time float32, stats balance.Enemy) enemies.Enemy
+	}{
+		{basic.Name, func(pos rl.Vector2, texture rl.Texture2D, time float32, stats balance.Enemy) enemies.Enemy {
+			return basic.FromPos(pos, texture, time, stats)
+		}},
+		{fast.Name, func(pos rl.Vector2, texture rl.Texture2D, time float32, stats balance.Enemy) enemies.Enemy {
+			return fast.FromPos(pos, texture, time, stats)
+		}},
+		{tank.Name, func(pos rl.Vector2, texture rl.Texture2D, time float32, stats balance.Enemy) enemies.Enemy {
+			return tank.FromPos(pos, texture, time, stats)
+		}},
+	}
+
+	for _, tt := range types {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := rl.Vector2{X: 100, Y: 100}
+
+			t.Run("stats come from profile", func(t *testing.T) {
+				e := tt.new(pos, testTexture, 0, testStats)
+				if e.Name() != tt.name {
+					t.Errorf("got name %s, want %s", e.Name(), tt.name)
+				}
+				if e.DealDamage() != 5 {
+					t.Errorf("got damage %v, want 5", e.DealDamage())
+				}
+				if got, want := e.Reward(), enemies.Reward(100, 2); got != want {
+					t.Errorf("got reward %d, want %d", got, want)
+				}
+				want := rl.Rectangle{X: 100, Y: 100, Width: 16, Height: 16}
+				if e.Boundaries() != want {
+					t.Errorf("got boundaries %v, want %v", e.Boundaries(), want)
+				}
+			})
+
+			t.Run("stats scale with time", func(t *testing.T) {
+				e := tt.new(pos, testTexture, 60, testStats)
+				if e.DealDamage() != 10 {
+					t.Errorf("got damage %v after a minute, want 10", e.DealDamage())
+				}
+			})
+
+			t.Run("steers with its weights", func(t *testing.T) {
+				e := tt.new(pos, testTexture, 0, testStats)
+				target := rl.Vector2{X: 200, Y: 100}
+				if _, ok := e.CustomMove(target); ok {
+					t.Errorf("built-in enemy shouldn't have custom move")
+				}
+				if e.Steering() != testStats.Steering {
+					t.Fatalf("got steering %v, want %v", e.Steering(), testStats.Steering)
+				}
+
+				speed := e.GetVelocity().Speed
+				seek := steering.Arrive(e.GetPos(), target, speed, e.Steering().Arrival)
+				move := steering.Forces{Seek: seek}.Blend(e.Steering(), speed)
+				if want := (rl.Vector2{X: 2}); move != want {
+					t.Errorf("towards: got %v, want %v", move, want)
+				}
+
+				// flare at the target pushes harder than target pulls
+				flee := steering.Flee(e.GetPos(), target, speed, 200)
+				move = steering.Forces{Seek: seek, Flee: flee}.Blend(e.Steering(), speed)
+				if move.X >= 0 {
+					t.Errorf("away: got %v, want enemy to back off from flare", move)
+				}
+			})
+
+			t.Run("dies from damage", func(t *testing.T) {
+				e := tt.new(pos, testTexture, 0, testStats)
+				e.TakeDamage(99)
+				if e.IsDead() {
+					t.Errorf("dead after 99 damage out of 100")
+				}
+				e.TakeDamage(1)
+				if !e.IsDead() {
+					t.Errorf("alive after 100 damage out of 100")
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package enemies
+
+import (
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+type Affix int
+
+const (
+	Armoured      Affix = iota + 1 // takes flat amount off every hit
+	Vampiric                       // heals part of damage dealt to soldiers
+	Fast                           // moves faster
+	Explosive                      // leaves a blast on death
+	FlareShielded                  // isn't repelled by flares
+)
+
+var allAffixes = []Affix{Armoured, Vampiric, Fast, Explosive, FlareShielded}
+
+var affixColors = map[Affix]rl.Color{
+	Armoured:      rl.LightGray,
+	Vampiric:      rl.Maroon,
+	Fast:          rl.SkyBlue,
+	Explosive:     rl.Orange,
+	FlareShielded: rl.Gold,
+}
+
+// eliteTint is multiplied with texture of every elite
+var eliteTint = rl.Color{R: 255, G: 190, B: 150, A: 255}
+
+// minArmouredDamage is part of every hit that gets through armour,
+// so burns and weak soldiers still hurt armoured elites
+const minArmouredDamage = 0.25
+
+// EliteChance is a chance of enemy spawned at given game time to be elite
+func EliteChance(time float32, stats balance.Elite) float32 {
+	if time < stats.UnlockAt {
+		return 0
+	}
+	return min(1, stats.Chance+stats.ChancePerMinute*(time-stats.UnlockAt)/60)
+}
+
+// RollAffixes returns random affixes for enemy spawned at given game time, nil if enemy isn't elite
+func RollAffixes(time float32, stats balance.Elite) []Affix {
+	if rand.Float32() >= EliteChance(time, stats) {
+		return nil
+	}
+	count := 1 + rand.Intn(min(stats.MaxAffixes, len(allAffixes)))
+	affixes := make([]Affix, 0, count)
+	for _, i := range rand.Perm(len(allAffixes))[:count] {
+		affixes = append(affixes, allAffixes[i])
+	}
+	return affixes
+}
+
+// Elite is a component of enemies that rolled affixes on spawn, zero value is a regular enemy
+type Elite struct {
+	affixes          []Affix
+	armour           float32
+	vampiric         float32
+	rewardMultiplier float32
+}
+
+func (e *Elite) GetElite() *Elite {
+	return e
+}
+
+func (e *Elite) IsElite() bool {
+	return len(e.affixes) > 0
+}
+
+func (e *Elite) Affixes() []Affix {
+	return e.affixes
+}
+
+func (e *Elite) Has(affix Affix) bool {
+	for _, a := range e.affixes {
+		if a == affix {
+			return true
+		}
+	}
+	return false
+}
+
+// RewardMultiplier is what reward of enemy is multiplied by, 1 for regular enemies
+func (e *Elite) RewardMultiplier() float32 {
+	if !e.IsElite() {
+		return 1
+	}
+	return e.rewardMultiplier
+}
+
+// reduce returns damage that gets through armour
+func (e *Elite) reduce(damage float32) float32 {
+	if e.armour == 0 {
+		return damage
+	}
+	return max(damage-e.armour, damage*minArmouredDamage)
+}
+
+// drawOutlines draws a frame of affix color around boundaries for every affix
+func (e *Elite) drawOutlines(boundaries rl.Rectangle) {
+	for i, a := range e.affixes {
+		offset := float32(2*i + 1)
+		frame := rl.Rectangle{
+			X:      boundaries.X - offset,
+			Y:      boundaries.Y - offset,
+			Width:  boundaries.Width + 2*offset,
+			Height: boundaries.Height + 2*offset,
+		}
+		rl.DrawRectangleLinesEx(frame, 1, affixColors[a])
+	}
+}
+
+// MakeElite gives enemy affixes, enemy spawned at later game time gets more armour
+func (b *Base) MakeElite(affixes []Affix, time float32, stats balance.Elite) {
+	if len(affixes) == 0 {
+		return
+	}
+	b.Elite = Elite{
+		affixes:          affixes,
+		rewardMultiplier: 1 + stats.RewardPerAffix*float32(len(affixes)),
+	}
+	if b.Has(Armoured) {
+		b.armour = ScaledStat(stats.Armour.From, stats.Armour.To, time)
+	}
+	if b.Has(Vampiric) {
+		b.vampiric = stats.Vampiric
+	}
+	if b.Has(Fast) {
+		b.Speed *= stats.Speed
+	}
+}
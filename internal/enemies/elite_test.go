@@ -0,0 +1,132 @@
+package enemies
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+var testEliteStats = balance.Elite{
+	UnlockAt:        120,
+	Chance:          0.1,
+	ChancePerMinute: 0.2,
+	MaxAffixes:      2,
+	RewardPerAffix:  1,
+	Armour:          balance.Range{From: 4, To: 4},
+	Vampiric:        0.5,
+	Speed:           2,
+}
+
+func newTestBase() Base {
+	return NewBase(rl.Vector2{}, 0, Profile{
+		Name:    "basic",
+		Texture: rl.Texture2D{Width: 16, Height: 16},
+		Stats: balance.Enemy{
+			Speed:  balance.Range{From: 2, To: 2},
+			Health: balance.Range{From: 100, To: 100},
+			Damage: balance.Range{From: 5, To: 5},
+		},
+	})
+}
+
+func TestEliteChance(t *testing.T) {
+	cases := []struct {
+		time float32
+		want float32
+	}{
+		{time: 0, want: 0},
+		{time: 119, want: 0},
+		{time: 120, want: 0.1},
+		{time: 180, want: 0.3},
+		{time: 600, want: 1},
+	}
+	for _, c := range cases {
+		if got := EliteChance(c.time, testEliteStats); got != c.want {
+			t.Errorf("at %vs: got %v, want %v", c.time, got, c.want)
+		}
+	}
+}
+
+func TestRollAffixes(t *testing.T) {
+	if got := RollAffixes(0, testEliteStats); got != nil {
+		t.Errorf("got affixes %v before unlock", got)
+	}
+
+	for range 100 {
+		affixes := RollAffixes(600, testEliteStats)
+		if len(affixes) == 0 || len(affixes) > testEliteStats.MaxAffixes {
+			t.Fatalf("got %d affixes, want 1 to %d", len(affixes), testEliteStats.MaxAffixes)
+		}
+		if len(affixes) == 2 && affixes[0] == affixes[1] {
+			t.Fatalf("got affix %v twice", affixes[0])
+		}
+	}
+}
+
+func TestMakeElite(t *testing.T) {
+	t.Run("regular enemy", func(t *testing.T) {
+		b := newTestBase()
+		reward := b.Reward()
+		b.MakeElite(nil, 0, testEliteStats)
+
+		if b.IsElite() {
+			t.Errorf("enemy without affixes is elite")
+		}
+		if got := b.Reward(); got != reward {
+			t.Errorf("got reward %d, want %d", got, reward)
+		}
+	})
+
+	t.Run("reward grows with affixes", func(t *testing.T) {
+		b := newTestBase()
+		reward := b.Reward()
+		b.MakeElite([]Affix{Vampiric, Explosive}, 0, testEliteStats)
+
+		if got, want := b.Reward(), reward*3; got != want {
+			t.Errorf("got reward %d, want %d", got, want)
+		}
+	})
+
+	t.Run("armour takes damage off", func(t *testing.T) {
+		b := newTestBase()
+		b.MakeElite([]Affix{Armoured}, 0, testEliteStats)
+
+		b.TakeDamage(10)
+		if b.HP != 94 {
+			t.Errorf("got health %v, want 94", b.HP)
+		}
+		// weak hits still deal part of their damage
+		b.TakeDamage(4)
+		if b.HP != 93 {
+			t.Errorf("got health %v, want 93", b.HP)
+		}
+	})
+
+	t.Run("vampiric heals on hit", func(t *testing.T) {
+		b := newTestBase()
+		b.MakeElite([]Affix{Vampiric}, 0, testEliteStats)
+		b.TakeDamage(20)
+
+		b.OnHit(10)
+		if b.HP != 85 {
+			t.Errorf("got health %v, want 85", b.HP)
+		}
+		b.OnHit(100)
+		if b.HP != b.MaxHP {
+			t.Errorf("got health %v, want it capped at %v", b.HP, b.MaxHP)
+		}
+	})
+
+	t.Run("fast and flare shielded", func(t *testing.T) {
+		b := newTestBase()
+		b.MakeElite([]Affix{Fast, FlareShielded}, 0, testEliteStats)
+
+		if b.Speed != 4 {
+			t.Errorf("got speed %v, want 4", b.Speed)
+		}
+		if !b.IgnoresFlares() {
+			t.Errorf("flare shielded enemy doesn't ignore flares")
+		}
+	})
+}
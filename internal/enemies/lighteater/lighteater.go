@@ -0,0 +1,37 @@
+package lighteater
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
+)
+
+// Name is the key of enemy stats in balance file
+const Name = "lighteater"
+
+// Enemy walks to the nearest flare and eats its light, once there are no flares it hunts soldiers
+type Enemy struct {
+	enemies.Base
+
+	drain float32
+}
+
+func FromPos(pos rl.Vector2, texture rl.Texture2D, time float32, stats balance.Enemy) *Enemy {
+	return &Enemy{
+		Base: enemies.NewBase(pos, time, enemies.Profile{
+			Name:    Name,
+			Texture: texture,
+			Stats:   stats,
+		}),
+		drain: stats.LightEater.Drain,
+	}
+}
+
+func (e *Enemy) Drain() float32 {
+	return e.drain
+}
+
+func (e *Enemy) Rebalance(prev, next balance.Enemy) {
+	e.Base.Rebalance(prev, next)
+	e.drain = next.LightEater.Drain
+}
@@ -0,0 +1,87 @@
+package enemies
+
+import (
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/pkg/rlutils"
+)
+
+// Type is an enemy type spawner can choose from
+type Type[E any] struct {
+	Name string
+	New  func(pos rl.Vector2, time float32) E
+	// Group creates the whole pack at once, so members can share state.
+	// If it's nil, pack members are created by New one by one.
+	Group func(positions []rl.Vector2, time float32) []E
+	Pack  int // how many enemies are spawned together
+	// Weight is a chance to be picked relative to other unlocked types at given game time
+	Weight   func(time float32) float32
+	UnlockAt float32 // seconds of game time
+}
+
+// Registry picks enemy types by their weights
+type Registry[E any] struct {
+	types []Type[E]
+}
+
+// Register adds enemy type, types are picked in the order of registration
+func (r *Registry[E]) Register(t Type[E]) {
+	for _, registered := range r.types {
+		if registered.Name == t.Name {
+			panic("enemy type " + t.Name + " is already registered")
+		}
+	}
+	r.types = append(r.types, t)
+}
+
+// Pick chooses type using roll in [0, 1)
+func (r *Registry[E]) Pick(time, roll float32) (Type[E], bool) {
+	weights := make([]float32, len(r.types))
+	for i, t := range r.types {
+		if time < t.UnlockAt {
+			continue
+		}
+		weights[i] = t.Weight(time)
+	}
+	i, ok := rlutils.PickWeighted(weights, roll)
+	if !ok {
+		return Type[E]{}, false
+	}
+	return r.types[i], true
+}
+
+// Roll picks random type, false if no type is unlocked yet
+func (r *Registry[E]) Roll(time float32) (Type[E], bool) {
+	return r.Pick(time, rand.Float32())
+}
+
+// Get returns type by name, even if it's not unlocked or can't be picked
+func (r *Registry[E]) Get(name string) (Type[E], bool) {
+	for _, t := range r.types {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Type[E]{}, false
+}
+
+// Spawn creates one enemy per position
+func (t Type[E]) Spawn(positions []rl.Vector2, time float32) []E {
+	if t.Group != nil {
+		return t.Group(positions, time)
+	}
+	spawned := make([]E, 0, len(positions))
+	for _, pos := range positions {
+		spawned = append(spawned, t.New(pos, time))
+	}
+	return spawned
+}
+
+// SpawnWeight is a weight curve described in balance
+func SpawnWeight(spawn balance.Spawn) func(time float32) float32 {
+	return func(time float32) float32 {
+		return spawn.Weight + spawn.WeightPerMinute*time/60
+	}
+}
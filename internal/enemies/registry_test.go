@@ -0,0 +1,80 @@
+package enemies
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+func newTestRegistry() *Registry[string] {
+	r := &Registry[string]{}
+	register := func(name string, spawn balance.Spawn) {
+		r.Register(Type[string]{
+			Name:     name,
+			New:      func(rl.Vector2, float32) string { return name },
+			Weight:   SpawnWeight(spawn),
+			UnlockAt: spawn.UnlockAt,
+		})
+	}
+	register("basic", balance.Spawn{Weight: 50})
+	register("fast", balance.Spawn{Weight: 35})
+	register("tank", balance.Spawn{Weight: 15})
+	register("late", balance.Spawn{Weight: 0, WeightPerMinute: 100, UnlockAt: 120})
+	return r
+}
+
+func TestRegistryPick(t *testing.T) {
+	r := newTestRegistry()
+
+	cases := []struct {
+		time float32
+		roll float32
+		want string
+	}{
+		{time: 0, roll: 0, want: "basic"},
+		{time: 0, roll: 0.49, want: "basic"},
+		{time: 0, roll: 0.5, want: "fast"},
+		{time: 0, roll: 0.84, want: "fast"},
+		{time: 0, roll: 0.85, want: "tank"},
+		{time: 0, roll: 0.9999999, want: "tank"},
+		// late is unlocked at 2 minutes with weight 200, total weight is 300
+		{time: 120, roll: 0.2, want: "fast"},
+		{time: 120, roll: 0.3, want: "tank"},
+		{time: 120, roll: 0.5, want: "late"},
+		{time: 119, roll: 0.99, want: "tank"},
+	}
+	for _, tc := range cases {
+		got, ok := r.Pick(tc.time, tc.roll)
+		if !ok {
+			t.Fatalf("time %v roll %v: nothing picked", tc.time, tc.roll)
+		}
+		if got.Name != tc.want {
+			t.Errorf("time %v roll %v: got %s, want %s", tc.time, tc.roll, got.Name, tc.want)
+		}
+	}
+}
+
+func TestRegistryNothingUnlocked(t *testing.T) {
+	r := &Registry[string]{}
+	r.Register(Type[string]{
+		Name:     "late",
+		Weight:   SpawnWeight(balance.Spawn{Weight: 1}),
+		UnlockAt: 60,
+	})
+
+	if _, ok := r.Roll(30); ok {
+		t.Errorf("locked type was picked")
+	}
+}
+
+func TestRegistryDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registering the same name twice didn't panic")
+		}
+	}()
+
+	r := newTestRegistry()
+	r.Register(Type[string]{Name: "basic"})
+}
@@ -0,0 +1,60 @@
+package spitter
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
+	"github.com/pechorka/illuminate-game-jam/internal/projectile"
+)
+
+// Name is the key of enemy stats in balance file
+const Name = "spitter"
+
+// keepDistance is part of shooting range spitter runs away from soldiers at
+const keepDistance = 0.7
+
+// Enemy keeps its distance from soldiers and spits at them
+type Enemy struct {
+	enemies.Base
+
+	ranged   balance.Ranged
+	shootAgo float32
+}
+
+func FromPos(pos rl.Vector2, texture rl.Texture2D, time float32, stats balance.Enemy) *Enemy {
+	return &Enemy{
+		Base: enemies.NewBase(pos, time, enemies.Profile{
+			Name:    Name,
+			Texture: texture,
+			Stats:   stats,
+		}),
+		ranged: *stats.Ranged,
+	}
+}
+
+func (e *Enemy) Rebalance(prev, next balance.Enemy) {
+	e.Base.Rebalance(prev, next)
+	e.ranged = *next.Ranged
+}
+
+// CustomMove keeps soldier at the edge of shooting range
+func (e *Enemy) CustomMove(target rl.Vector2) (rl.Vector2, bool) {
+	dist := rl.Vector2Distance(e.Pos, target)
+	switch {
+	case dist < e.ranged.Range*keepDistance:
+		return e.MoveAway(target), true
+	case dist <= e.ranged.Range:
+		return e.Pos, true
+	}
+	return rl.Vector2{}, false
+}
+
+func (e *Enemy) Shoot(target rl.Vector2, dt float32) (*projectile.Projectile, bool) {
+	e.shootAgo += dt
+	if e.shootAgo < e.ranged.Rate || rl.Vector2Distance(e.Pos, target) > e.ranged.Range {
+		return nil, false
+	}
+	e.shootAgo = 0
+	velocity := rl.Vector2Subtract(target, e.Pos)
+	return projectile.FromEnemy(e.Pos, velocity, e.ranged.ProjectileSpeed, e.DealDamage()), true
+}
@@ -0,0 +1,76 @@
+package spitter
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
+)
+
+var testStats = balance.Enemy{
+	Speed:  balance.Range{From: 1, To: 1},
+	Health: balance.Range{From: 10, To: 10},
+	Damage: balance.Range{From: 5, To: 5},
+	Ranged: &balance.Ranged{Range: 100, Rate: 2, ProjectileSpeed: 3},
+}
+
+func TestCustomMove(t *testing.T) {
+	e := FromPos(rl.Vector2{X: 0, Y: 0}, rl.Texture2D{}, 0, testStats)
+
+	cases := []struct {
+		name   string
+		target rl.Vector2
+		want   rl.Vector2
+		ok     bool
+	}{
+		{name: "too close runs away", target: rl.Vector2{X: 50}, want: rl.Vector2{X: -1}, ok: true},
+		{name: "in range stays", target: rl.Vector2{X: 90}, want: rl.Vector2{}, ok: true},
+		{name: "out of range walks as usual", target: rl.Vector2{X: 150}, ok: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := e.CustomMove(tc.target)
+			if ok != tc.ok {
+				t.Fatalf("got ok %v, want %v", ok, tc.ok)
+			}
+			if ok && got != tc.want {
+				t.Errorf("got pos %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestShoot(t *testing.T) {
+	t.Run("shoots once per rate", func(t *testing.T) {
+		e := FromPos(rl.Vector2{}, rl.Texture2D{}, 0, testStats)
+		target := rl.Vector2{X: 80}
+
+		if _, ok := e.Shoot(target, 1); ok {
+			t.Errorf("shot before reloading")
+		}
+		p, ok := e.Shoot(target, 1)
+		if !ok {
+			t.Fatalf("didn't shoot after reloading")
+		}
+		if p.Team != ecs.TeamEnemies {
+			t.Errorf("got team %v, want enemies", p.Team)
+		}
+		if p.Shooter != nil {
+			t.Errorf("enemy projectile has shooter to credit")
+		}
+		if p.DealDamage() != 5 || p.Speed != 3 {
+			t.Errorf("got damage %v and speed %v, want 5 and 3", p.DealDamage(), p.Speed)
+		}
+		if _, ok := e.Shoot(target, 1); ok {
+			t.Errorf("shot twice without reloading")
+		}
+	})
+
+	t.Run("doesn't shoot out of range", func(t *testing.T) {
+		e := FromPos(rl.Vector2{}, rl.Texture2D{}, 0, testStats)
+		if _, ok := e.Shoot(rl.Vector2{X: 150}, 10); ok {
+			t.Errorf("shot out of range")
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package splitter
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
+)
+
+// Name is the key of enemy stats in balance file
+const Name = "splitter"
+
+// splitDistance is how far from each other children appear
+const splitDistance = 30
+
+// Enemy splits into smaller enemies when it dies
+type Enemy struct {
+	enemies.Base
+
+	split    balance.Split
+	newChild func(pos rl.Vector2) enemies.Enemy
+	children []enemies.Enemy
+}
+
+func FromPos(
+	pos rl.Vector2,
+	texture rl.Texture2D,
+	time float32,
+	stats balance.Enemy,
+	newChild func(pos rl.Vector2) enemies.Enemy,
+) *Enemy {
+	return &Enemy{
+		Base: enemies.NewBase(pos, time, enemies.Profile{
+			Name:    Name,
+			Texture: texture,
+			Stats:   stats,
+		}),
+		split:    *stats.Split,
+		newChild: newChild,
+	}
+}
+
+func (e *Enemy) Rebalance(prev, next balance.Enemy) {
+	e.Base.Rebalance(prev, next)
+	e.split = *next.Split
+}
+
+// OnDeath places children in a row across the place splitter died at
+func (e *Enemy) OnDeath() {
+	rowWidth := float32(e.split.Count-1) * splitDistance
+	for i := range e.split.Count {
+		pos := rl.Vector2{
+			X: e.Pos.X - rowWidth/2 + float32(i)*splitDistance,
+			Y: e.Pos.Y,
+		}
+		e.children = append(e.children, e.newChild(pos))
+	}
+}
+
+func (e *Enemy) Summoned() []enemies.Enemy {
+	children := e.children
+	e.children = nil
+	return children
+}
+
+// ChildName is the key of splitter children stats in balance file
+const ChildName = "splitling"
+
+// Child is a small enemy splitter splits into
+type Child struct {
+	enemies.Base
+}
+
+func ChildFromPos(pos rl.Vector2, texture rl.Texture2D, time float32, stats balance.Enemy) *Child {
+	return &Child{
+		Base: enemies.NewBase(pos, time, enemies.Profile{
+			Name:    ChildName,
+			Texture: texture,
+			Stats:   stats,
+		}),
+	}
+}
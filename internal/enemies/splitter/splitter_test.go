@@ -0,0 +1,44 @@
+package splitter
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
+)
+
+func TestSplit(t *testing.T) {
+	stats := balance.Enemy{
+		Speed:  balance.Range{From: 1, To: 1},
+		Health: balance.Range{From: 10, To: 10},
+		Split:  &balance.Split{Into: ChildName, Count: 3},
+	}
+	e := FromPos(rl.Vector2{X: 100, Y: 100}, rl.Texture2D{}, 0, stats, func(pos rl.Vector2) enemies.Enemy {
+		child := ChildFromPos(pos, rl.Texture2D{}, 0, stats)
+		return child
+	})
+
+	if got := len(e.Summoned()); got != 0 {
+		t.Fatalf("got %d children before death, want 0", got)
+	}
+
+	e.TakeDamage(10)
+	e.OnDeath()
+	children := e.Summoned()
+
+	if len(children) != 3 {
+		t.Fatalf("got %d children, want 3", len(children))
+	}
+	for i, want := range []rl.Vector2{{X: 70, Y: 100}, {X: 100, Y: 100}, {X: 130, Y: 100}} {
+		if got := children[i].GetPos(); got != want {
+			t.Errorf("child %d: got pos %v, want %v", i, got, want)
+		}
+		if got := children[i].Name(); got != ChildName {
+			t.Errorf("child %d: got name %s, want %s", i, got, ChildName)
+		}
+	}
+	if got := len(e.Summoned()); got != 0 {
+		t.Errorf("got %d children on second call, want 0", got)
+	}
+}
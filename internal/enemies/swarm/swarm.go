@@ -0,0 +1,97 @@
+package swarm
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
+)
+
+// Name is the key of enemy stats in balance file
+const Name = "swarm"
+
+// Pack is a group of swarm enemies that move with a shared heading.
+// Heading is chosen by the leader, the first alive member of the pack, game loop navigates it.
+type Pack struct {
+	members []*Enemy
+	heading rl.Vector2 // normalized, zero until leader chose it
+	speed   float32
+}
+
+// NewPack creates pack with one member per position, pack moves with the speed of the first member
+func NewPack(positions []rl.Vector2, texture rl.Texture2D, time float32, stats balance.Enemy) *Pack {
+	p := &Pack{}
+	for _, pos := range positions {
+		p.members = append(p.members, &Enemy{
+			Base: enemies.NewBase(pos, time, enemies.Profile{
+				Name:    Name,
+				Texture: texture,
+				Stats:   stats,
+			}),
+			pack: p,
+		})
+	}
+	if len(p.members) > 0 {
+		p.speed = p.members[0].Speed
+	}
+	return p
+}
+
+func (p *Pack) Members() []*Enemy {
+	return p.members
+}
+
+func (p *Pack) Heading() rl.Vector2 {
+	return p.heading
+}
+
+func (p *Pack) leader() *Enemy {
+	for _, m := range p.members {
+		if !m.IsDead() {
+			return m
+		}
+	}
+	return nil
+}
+
+// Enemy is a member of a pack
+type Enemy struct {
+	enemies.Base
+
+	pack        *Pack
+	nextHeading rl.Vector2
+}
+
+// CustomMove moves along the pack heading, target is ignored since leader steers the pack with Lead
+func (e *Enemy) CustomMove(target rl.Vector2) (rl.Vector2, bool) {
+	if e.pack.heading == (rl.Vector2{}) {
+		return rl.Vector2{}, false
+	}
+	return rl.Vector2Add(e.Pos, rl.Vector2Scale(e.pack.heading, e.pack.speed)), true
+}
+
+func (e *Enemy) Leads() bool {
+	return e.pack.leader() == e
+}
+
+// Lead only remembers where leader wants to go, since other members read the heading concurrently,
+// heading changes in ProgressTime
+func (e *Enemy) Lead(dir rl.Vector2) {
+	e.nextHeading = dir
+}
+
+func (e *Enemy) ProgressTime(dt float32) {
+	if e.pack.leader() == e {
+		e.pack.heading = e.nextHeading
+	}
+}
+
+func (e *Enemy) Rebalance(prev, next balance.Enemy) {
+	e.Base.Rebalance(prev, next)
+	if e.pack.leader() == e {
+		e.pack.speed = e.Speed
+	}
+}
+
+func (e *Enemy) Pack() *Pack {
+	return e.pack
+}
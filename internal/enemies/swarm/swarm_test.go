@@ -0,0 +1,61 @@
+package swarm
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+var testStats = balance.Enemy{
+	Speed:  balance.Range{From: 2, To: 2},
+	Health: balance.Range{From: 10, To: 10},
+}
+
+// step does what game loop does: leads pack straight to target, computes moves, then progresses time and moves
+func step(p *Pack, target rl.Vector2) {
+	moves := make([]rl.Vector2, len(p.members))
+	moved := make([]bool, len(p.members))
+	for i, m := range p.members {
+		if m.Leads() {
+			m.Lead(rl.Vector2Normalize(rl.Vector2Subtract(target, m.Pos)))
+		}
+		moves[i], moved[i] = m.CustomMove(target)
+	}
+	for i, m := range p.members {
+		m.ProgressTime(1)
+		if moved[i] {
+			m.UpdatePosition(moves[i])
+		}
+	}
+}
+
+func TestPack(t *testing.T) {
+	t.Run("members share leader heading", func(t *testing.T) {
+		p := NewPack([]rl.Vector2{{X: 0, Y: 0}, {X: 0, Y: 50}, {X: 0, Y: 100}}, rl.Texture2D{}, 0, testStats)
+
+		step(p, rl.Vector2{X: 100, Y: 0})
+		step(p, rl.Vector2{X: 100, Y: 0})
+
+		if got, want := p.Heading(), (rl.Vector2{X: 1, Y: 0}); got != want {
+			t.Errorf("got heading %v, want %v", got, want)
+		}
+		for i, want := range []rl.Vector2{{X: 2, Y: 0}, {X: 2, Y: 50}, {X: 2, Y: 100}} {
+			if got := p.members[i].Pos; got != want {
+				t.Errorf("member %d: got pos %v, want %v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("next member leads when leader dies", func(t *testing.T) {
+		p := NewPack([]rl.Vector2{{X: 0, Y: 0}, {X: 0, Y: 50}}, rl.Texture2D{}, 0, testStats)
+		step(p, rl.Vector2{X: 100, Y: 0})
+
+		p.members[0].TakeDamage(10)
+		step(p, rl.Vector2{X: 0, Y: 150})
+
+		if got, want := p.Heading(), (rl.Vector2{X: 0, Y: 1}); got != want {
+			t.Errorf("got heading %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package enemies
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/projectile"
+)
+
+// Optional behaviours game loop checks enemies for.
+
+// Ranged enemy shoots at soldiers
+type Ranged interface {
+	// Shoot is called every frame with position of the nearest soldier,
+	// returns projectile if enemy shoots this frame
+	Shoot(target rl.Vector2, dt float32) (*projectile.Projectile, bool)
+}
+
+// Timed enemy has timers that run with game time
+type Timed interface {
+	ProgressTime(dt float32)
+}
+
+// Summoner enemy brings other enemies to the arena
+type Summoner interface {
+	// Summoned returns enemies summoned since the last call
+	Summoned() []Enemy
+}
+
+// FlareImmune enemy can ignore flare repulsion
+type FlareImmune interface {
+	IgnoresFlares() bool
+}
+
+// LightEater is attracted to flares instead of being repelled by them
+type LightEater interface {
+	// Drain is a factor radius of flare is multiplied by every frame enemy is in its light
+	Drain() float32
+}
+
+// Flock enemy moves along heading its pack shares, game loop navigates only the leader
+type Flock interface {
+	// Leads reports whether enemy chooses heading of its pack
+	Leads() bool
+	// Lead sets normalized direction pack heads to from the next frame
+	Lead(dir rl.Vector2)
+}
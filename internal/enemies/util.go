@@ -1,6 +1,9 @@
 package enemies
 
-import "github.com/pechorka/illuminate-game-jam/pkg/rlutils"
+import (
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/pkg/rlutils"
+)
 
 func Reward(health, speed float32) int {
 	return int(health*speed) / 10
@@ -12,3 +15,13 @@ func ScaledStat(min, max, time float32) float32 {
 	max *= multiplier
 	return rlutils.RandomFloat(min, max)
 }
+
+// Rescale moves stat rolled from prev range to the same relative place in next range
+func Rescale(value float32, prev, next balance.Range) float32 {
+	prevMid := (prev.From + prev.To) / 2
+	nextMid := (next.From + next.To) / 2
+	if prevMid == 0 {
+		return value
+	}
+	return value * nextMid / prevMid
+}
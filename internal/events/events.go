@@ -0,0 +1,34 @@
+// Package events is a synchronous typed event bus.
+//
+// Handlers are called in the order they subscribed, on the goroutine that
+// publishes, so everything that happens because of an event happens within
+// the same frame and in the same order every time.
+package events
+
+import "reflect"
+
+type Bus struct {
+	handlers map[reflect.Type][]func(any)
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[reflect.Type][]func(any))}
+}
+
+func Subscribe[E any](b *Bus, handler func(E)) {
+	key := reflect.TypeFor[E]()
+	b.handlers[key] = append(b.handlers[key], func(event any) {
+		handler(event.(E))
+	})
+}
+
+// Publish calls every handler subscribed to E before returning.
+// Publishing to nil bus does nothing, so code under test can skip the bus.
+func Publish[E any](b *Bus, event E) {
+	if b == nil {
+		return
+	}
+	for _, handler := range b.handlers[reflect.TypeFor[E]()] {
+		handler(event)
+	}
+}
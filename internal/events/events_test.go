@@ -0,0 +1,60 @@
+package events
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	t.Run("handlers are called in subscription order", func(t *testing.T) {
+		bus := NewBus()
+		var calls []string
+		Subscribe(bus, func(EnemyKilled) { calls = append(calls, "first") })
+		Subscribe(bus, func(EnemyKilled) { calls = append(calls, "second") })
+		Subscribe(bus, func(EnemyKilled) { calls = append(calls, "third") })
+
+		Publish(bus, EnemyKilled{})
+
+		want := []string{"first", "second", "third"}
+		if !slices.Equal(calls, want) {
+			t.Errorf("got %v, want %v", calls, want)
+		}
+	})
+
+	t.Run("handlers receive only their event type", func(t *testing.T) {
+		bus := NewBus()
+		killed, died := 0, 0
+		Subscribe(bus, func(EnemyKilled) { killed++ })
+		Subscribe(bus, func(SoldierDied) { died++ })
+
+		Publish(bus, EnemyKilled{Reward: 10})
+		Publish(bus, EnemyKilled{Reward: 20})
+		Publish(bus, SoldierDied{})
+
+		if killed != 2 || died != 1 {
+			t.Errorf("got %d kills and %d deaths, want 2 and 1", killed, died)
+		}
+	})
+
+	t.Run("event published from handler is handled before publish returns", func(t *testing.T) {
+		bus := NewBus()
+		var calls []string
+		Subscribe(bus, func(SoldierDamaged) {
+			calls = append(calls, "damaged")
+			Publish(bus, SoldierDied{})
+			calls = append(calls, "damaged done")
+		})
+		Subscribe(bus, func(SoldierDied) { calls = append(calls, "died") })
+
+		Publish(bus, SoldierDamaged{})
+
+		want := []string{"damaged", "died", "damaged done"}
+		if !slices.Equal(calls, want) {
+			t.Errorf("got %v, want %v", calls, want)
+		}
+	})
+
+	t.Run("publishing to nil bus does nothing", func(t *testing.T) {
+		Publish[RunStarted](nil, RunStarted{})
+	})
+}
@@ -0,0 +1,63 @@
+package events
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+type EnemyKilled struct {
+	EnemyID int
+	Pos     rl.Vector2
+	Reward  int
+}
+
+type SoldierDamaged struct {
+	SoldierID int
+	Damage    float32
+	HP        float32 // after damage
+}
+
+type SoldierLeveledUp struct {
+	SoldierID int
+	Level     int
+}
+
+type SoldierDied struct {
+	SoldierID int
+	Pos       rl.Vector2
+}
+
+type ItemBought struct {
+	Name  string
+	Price int
+	Count int
+}
+
+type ConsumableUsed struct {
+	Name string
+	Pos  rl.Vector2
+}
+
+// EscortEnded is published when VIP is escorted or lost
+type EscortEnded struct {
+	Escorted bool
+	Money    int // paid out when escorted, lost otherwise
+}
+
+type ContractSigned struct {
+	Name string
+}
+
+// ContractCompleted is published when contract runs out and pays its bonus
+type ContractCompleted struct {
+	Name  string
+	Cash  int
+	Score int
+}
+
+type RunStarted struct {
+	Soldiers int
+}
+
+type RunEnded struct {
+	Victory bool
+	Score   int
+	Time    float32
+}
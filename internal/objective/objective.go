@@ -0,0 +1,105 @@
+// Package objective contains victory conditions of endless mode.
+package objective
+
+import (
+	"fmt"
+
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+type Kind int
+
+const (
+	Dawn   Kind = iota // survive the night, enemies retreat at dawn
+	Kills              // kill enough enemies
+	Bosses             // defeat enough bosses
+)
+
+// Kinds in the order they are shown on setup screen
+var Kinds = []Kind{Dawn, Kills, Bosses}
+
+func (k Kind) String() string {
+	switch k {
+	case Kills:
+		return "Kills"
+	case Bosses:
+		return "Bosses"
+	}
+	return "Dawn"
+}
+
+// Progress is what objective knows about the run when it's checked
+type Progress struct {
+	Time        float32 // game time
+	Kills       int
+	Bosses      int // bosses killed
+	EnemiesLeft int // alive enemies and those about to spawn
+}
+
+type Objective struct {
+	kind  Kind
+	stats balance.Objectives
+}
+
+func New(kind Kind, stats balance.Objectives) *Objective {
+	return &Objective{kind: kind, stats: stats}
+}
+
+// Rebalance changes stats, progress made so far is kept
+func (o *Objective) Rebalance(stats balance.Objectives) {
+	o.stats = stats
+}
+
+func (o *Objective) Kind() Kind {
+	return o.kind
+}
+
+// Retreating reports whether dawn came and enemies run away instead of attacking
+func (o *Objective) Retreating(time float32) bool {
+	return o.kind == Dawn && time >= o.stats.Dawn
+}
+
+// Daylight is how bright the sky is, it goes from 0 at the start of twilight to 1 at dawn
+func (o *Objective) Daylight(time float32) float32 {
+	if o.kind != Dawn {
+		return 0
+	}
+	twilightAt := o.stats.Dawn - o.stats.Twilight
+	if time < twilightAt {
+		return 0
+	}
+	if o.stats.Twilight <= 0 {
+		return 1
+	}
+	return min(1, (time-twilightAt)/o.stats.Twilight)
+}
+
+// Done reports whether the run is won. At dawn it's won once every enemy
+// retreated, or once retreat time is over.
+func (o *Objective) Done(p Progress) bool {
+	switch o.kind {
+	case Kills:
+		return p.Kills >= o.stats.Kills
+	case Bosses:
+		return p.Bosses >= o.stats.Bosses
+	}
+	if !o.Retreating(p.Time) {
+		return false
+	}
+	return p.EnemiesLeft == 0 || p.Time >= o.stats.Dawn+o.stats.Retreat
+}
+
+// Status is objective progress shown in header
+func (o *Objective) Status(p Progress) string {
+	switch o.kind {
+	case Kills:
+		return fmt.Sprintf("Kills: %d/%d", min(p.Kills, o.stats.Kills), o.stats.Kills)
+	case Bosses:
+		return fmt.Sprintf("Bosses: %d/%d", min(p.Bosses, o.stats.Bosses), o.stats.Bosses)
+	}
+	if o.Retreating(p.Time) {
+		return "Dawn has come"
+	}
+	left := int(o.stats.Dawn - p.Time + 0.999) // round up, so 0:00 is shown only at dawn
+	return fmt.Sprintf("Dawn in %d:%02d", left/60, left%60)
+}
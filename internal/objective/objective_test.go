@@ -0,0 +1,76 @@
+package objective
+
+import (
+	"testing"
+
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+var testStats = balance.Objectives{Dawn: 600, Twilight: 120, Retreat: 10, Kills: 100, Bosses: 2}
+
+func TestDawn(t *testing.T) {
+	o := New(Dawn, testStats)
+
+	t.Run("sky brightens during twilight", func(t *testing.T) {
+		for _, tc := range []struct {
+			time float32
+			want float32
+		}{
+			{time: 100, want: 0},
+			{time: 540, want: 0.5},
+			{time: 700, want: 1},
+		} {
+			if got := o.Daylight(tc.time); got != tc.want {
+				t.Errorf("at %vs: got daylight %v, want %v", tc.time, got, tc.want)
+			}
+		}
+	})
+
+	t.Run("night goes on until dawn", func(t *testing.T) {
+		p := Progress{Time: 599.5}
+		if o.Retreating(p.Time) || o.Done(p) {
+			t.Errorf("dawn came early")
+		}
+		if got := o.Status(p); got != "Dawn in 0:01" {
+			t.Errorf("got status %q", got)
+		}
+	})
+
+	t.Run("run is won once enemies retreated", func(t *testing.T) {
+		p := Progress{Time: 601, EnemiesLeft: 3}
+		if !o.Retreating(p.Time) {
+			t.Fatalf("enemies don't retreat at dawn")
+		}
+		if o.Done(p) {
+			t.Errorf("won while enemies are in the arena")
+		}
+		p.EnemiesLeft = 0
+		if !o.Done(p) {
+			t.Errorf("not won after enemies retreated")
+		}
+	})
+
+	t.Run("run is won once retreat is over", func(t *testing.T) {
+		if !o.Done(Progress{Time: 610, EnemiesLeft: 3}) {
+			t.Errorf("not won after retreat")
+		}
+	})
+}
+
+func TestCounters(t *testing.T) {
+	kills := New(Kills, testStats)
+	if kills.Done(Progress{Kills: 99}) || !kills.Done(Progress{Kills: 100}) {
+		t.Errorf("kills objective isn't done at exactly %d kills", testStats.Kills)
+	}
+	if got := kills.Status(Progress{Kills: 120}); got != "Kills: 100/100" {
+		t.Errorf("got status %q", got)
+	}
+
+	bosses := New(Bosses, testStats)
+	if bosses.Done(Progress{Bosses: 1, Time: 1000}) || !bosses.Done(Progress{Bosses: 2}) {
+		t.Errorf("bosses objective isn't done at exactly %d bosses", testStats.Bosses)
+	}
+	if bosses.Retreating(1000) || bosses.Daylight(1000) != 0 {
+		t.Errorf("dawn came in bosses objective")
+	}
+}
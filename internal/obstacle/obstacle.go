@@ -0,0 +1,227 @@
+// Package obstacle contains static obstacles that block movement and projectiles.
+package obstacle
+
+import (
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
+)
+
+type Kind int
+
+const (
+	Wall Kind = iota
+	Rock
+	Ruin
+)
+
+var (
+	fillColors = map[Kind]rl.Color{
+		Wall: {R: 90, G: 90, B: 100, A: 255},
+		Rock: {R: 70, G: 65, B: 60, A: 255},
+		Ruin: {R: 95, G: 75, B: 55, A: 255},
+	}
+	edgeColor = rl.Color{R: 40, G: 40, B: 45, A: 255}
+)
+
+type Obstacle struct {
+	ecs.Entity
+
+	Kind   Kind
+	Bounds rl.Rectangle
+}
+
+func New(kind Kind, bounds rl.Rectangle) *Obstacle {
+	return &Obstacle{
+		Entity: ecs.NewEntity(),
+		Kind:   kind,
+		Bounds: bounds,
+	}
+}
+
+func (o *Obstacle) Boundaries() rl.Rectangle {
+	return o.Bounds
+}
+
+func (o *Obstacle) Draw() {
+	if o.Kind == Rock {
+		rl.DrawRectangleRounded(o.Bounds, 0.5, 6, fillColors[o.Kind])
+		rl.DrawRectangleRoundedLines(o.Bounds, 0.5, 6, 2, edgeColor)
+		return
+	}
+	rl.DrawRectangleRec(o.Bounds, fillColors[o.Kind])
+	rl.DrawRectangleLinesEx(o.Bounds, 2, edgeColor)
+}
+
+const (
+	// clearance is how many free cells are left around every obstacle,
+	// so obstacles never close a passage and arena stays connected
+	clearance = 2
+	// attempts to find a free place for one obstacle before giving up on it
+	placeAttempts = 50
+	ruinSize      = 5
+	nestSize      = 3
+)
+
+// cells is a rectangle measured in grid cells
+type cells struct {
+	x, y, w, h int
+}
+
+func (c cells) overlaps(other cells) bool {
+	return c.x < other.x+other.w && other.x < c.x+c.w &&
+		c.y < other.y+other.h && other.y < c.y+c.h
+}
+
+func (c cells) grow(by int) cells {
+	return cells{x: c.x - by, y: c.y - by, w: c.w + by*2, h: c.h + by*2}
+}
+
+// rect converts cells of grid that starts at bounds to pixels
+func (c cells) rect(bounds rl.Rectangle, cellSize float32) rl.Rectangle {
+	return rl.Rectangle{
+		X:      bounds.X + float32(c.x)*cellSize,
+		Y:      bounds.Y + float32(c.y)*cellSize,
+		Width:  float32(c.w) * cellSize,
+		Height: float32(c.h) * cellSize,
+	}
+}
+
+// piece is a part of a shape, positioned relative to shape's top left cell
+type piece struct {
+	kind Kind
+	cells
+}
+
+// Generate places obstacles described by stats randomly in bounds.
+// Obstacles are aligned to cells of stats.CellSize, and keep clearance
+// from each other and from arena edges. Obstacles that don't fit are skipped.
+func Generate(bounds rl.Rectangle, stats balance.Arena) []*Obstacle {
+	cols := int(bounds.Width / stats.CellSize)
+	rows := int(bounds.Height / stats.CellSize)
+
+	var shapes [][]piece
+	for range stats.Ruins {
+		shapes = append(shapes, ruin())
+	}
+	for range stats.Walls {
+		shapes = append(shapes, wall())
+	}
+	for range stats.Rocks {
+		shapes = append(shapes, rock())
+	}
+
+	var taken []cells
+	var obstacles []*Obstacle
+	for _, shape := range shapes {
+		placed, ok := place(footprint(shape), taken, cols, rows)
+		if !ok {
+			continue
+		}
+		taken = append(taken, placed)
+		for _, p := range shape {
+			p.x += placed.x
+			p.y += placed.y
+			obstacles = append(obstacles, New(p.kind, p.rect(bounds, stats.CellSize)))
+		}
+	}
+	return obstacles
+}
+
+// Nests places free square areas enemies crawl out of. Like obstacles, nests keep
+// clearance from obstacles and arena edges, nests that don't fit are skipped.
+func Nests(bounds rl.Rectangle, stats balance.Arena, obstacles []*Obstacle) []rl.Rectangle {
+	cols := int(bounds.Width / stats.CellSize)
+	rows := int(bounds.Height / stats.CellSize)
+
+	taken := make([]cells, 0, len(obstacles))
+	for _, o := range obstacles {
+		taken = append(taken, cells{
+			x: int((o.Bounds.X - bounds.X) / stats.CellSize),
+			y: int((o.Bounds.Y - bounds.Y) / stats.CellSize),
+			w: int(o.Bounds.Width / stats.CellSize),
+			h: int(o.Bounds.Height / stats.CellSize),
+		})
+	}
+
+	var nests []rl.Rectangle
+	for range stats.Nests {
+		placed, ok := place(cells{w: nestSize, h: nestSize}, taken, cols, rows)
+		if !ok {
+			continue
+		}
+		taken = append(taken, placed)
+		nests = append(nests, placed.rect(bounds, stats.CellSize))
+	}
+	return nests
+}
+
+// place finds random position for footprint that keeps clearance from taken cells and grid edges
+func place(footprint cells, taken []cells, cols, rows int) (cells, bool) {
+	maxX := cols - clearance - footprint.w
+	maxY := rows - clearance - footprint.h
+	if maxX < clearance || maxY < clearance {
+		return cells{}, false
+	}
+	for range placeAttempts {
+		placed := footprint
+		placed.x = clearance + rand.Intn(maxX-clearance+1)
+		placed.y = clearance + rand.Intn(maxY-clearance+1)
+		if !overlapsAny(placed.grow(clearance), taken) {
+			return placed, true
+		}
+	}
+	return cells{}, false
+}
+
+func overlapsAny(c cells, taken []cells) bool {
+	for _, t := range taken {
+		if c.overlaps(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func footprint(shape []piece) cells {
+	var f cells
+	for _, p := range shape {
+		f.w = max(f.w, p.x+p.w)
+		f.h = max(f.h, p.y+p.h)
+	}
+	return f
+}
+
+// wall is a straight line one cell thick
+func wall() []piece {
+	length := 4 + rand.Intn(5)
+	if rand.Intn(2) == 0 {
+		return []piece{{kind: Wall, cells: cells{w: length, h: 1}}}
+	}
+	return []piece{{kind: Wall, cells: cells{w: 1, h: length}}}
+}
+
+// rock is a small block
+func rock() []piece {
+	return []piece{{kind: Rock, cells: cells{w: 1 + rand.Intn(2), h: 1 + rand.Intn(2)}}}
+}
+
+// ruin is a square room with one side missing, so it can be entered
+func ruin() []piece {
+	sides := []cells{
+		{w: ruinSize, h: 1},                            // top
+		{y: ruinSize - 1, w: ruinSize, h: 1},           // bottom
+		{y: 1, w: 1, h: ruinSize - 2},                  // left
+		{x: ruinSize - 1, y: 1, w: 1, h: ruinSize - 2}, // right
+	}
+	missing := rand.Intn(len(sides))
+	var shape []piece
+	for i, side := range sides {
+		if i != missing {
+			shape = append(shape, piece{kind: Ruin, cells: side})
+		}
+	}
+	return shape
+}
@@ -0,0 +1,95 @@
+package obstacle
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/pkg/navgrid"
+)
+
+func TestGenerate(t *testing.T) {
+	bounds := rl.Rectangle{X: 0, Y: 36, Width: 1280, Height: 576}
+	stats := balance.Arena{CellSize: 32, Walls: 3, Rocks: 4, Ruins: 1}
+
+	for range 20 {
+		obstacles := Generate(bounds, stats)
+
+		counts := map[Kind]int{}
+		grid := navgrid.New(bounds, stats.CellSize)
+		for _, o := range obstacles {
+			counts[o.Kind]++
+			margin := stats.CellSize * clearance
+			inner := rl.Rectangle{
+				X:      bounds.X + margin,
+				Y:      bounds.Y + margin,
+				Width:  bounds.Width - margin*2,
+				Height: bounds.Height - margin*2,
+			}
+			if o.Bounds.X < inner.X || o.Bounds.Y < inner.Y ||
+				o.Bounds.X+o.Bounds.Width > inner.X+inner.Width ||
+				o.Bounds.Y+o.Bounds.Height > inner.Y+inner.Height {
+				t.Fatalf("obstacle %v is closer than %d cells to arena edge", o.Bounds, clearance)
+			}
+			if grid.Overlaps(o.Bounds) {
+				t.Fatalf("obstacle %v overlaps another obstacle", o.Bounds)
+			}
+			grid.Block(o.Bounds)
+		}
+		if counts[Wall] != stats.Walls || counts[Rock] != stats.Rocks || counts[Ruin] != 3*stats.Ruins {
+			t.Fatalf("got %v walls, rocks and ruin sides, want %d, %d and %d",
+				counts, stats.Walls, stats.Rocks, 3*stats.Ruins)
+		}
+
+		// every free cell is reachable from the top left corner
+		from := grid.Center(navgrid.Cell{})
+		for y := range grid.Rows() {
+			for x := range grid.Cols() {
+				c := navgrid.Cell{X: x, Y: y}
+				if grid.Blocked(c) {
+					continue
+				}
+				if grid.Path(from, grid.Center(c)) == nil {
+					t.Fatalf("cell %v can't be reached", c)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateSkipsWhatDoesntFit(t *testing.T) {
+	bounds := rl.Rectangle{Width: 160, Height: 160}
+	obstacles := Generate(bounds, balance.Arena{CellSize: 32, Ruins: 1})
+	if len(obstacles) != 0 {
+		t.Errorf("got %d obstacles, want none", len(obstacles))
+	}
+}
+
+func TestNests(t *testing.T) {
+	bounds := rl.Rectangle{X: 0, Y: 36, Width: 1280, Height: 576}
+	stats := balance.Arena{CellSize: 32, Walls: 3, Rocks: 4, Ruins: 1, Nests: 2}
+
+	for range 20 {
+		obstacles := Generate(bounds, stats)
+		nests := Nests(bounds, stats, obstacles)
+
+		if len(nests) != stats.Nests {
+			t.Fatalf("got %d nests, want %d", len(nests), stats.Nests)
+		}
+		for i, n := range nests {
+			if !rl.CheckCollisionRecs(bounds, n) {
+				t.Fatalf("nest %v is out of arena", n)
+			}
+			for _, o := range obstacles {
+				if rl.CheckCollisionRecs(n, o.Bounds) {
+					t.Fatalf("nest %v overlaps obstacle %v", n, o.Bounds)
+				}
+			}
+			for _, other := range nests[i+1:] {
+				if rl.CheckCollisionRecs(n, other) {
+					t.Fatalf("nests %v and %v overlap", n, other)
+				}
+			}
+		}
+	}
+}
@@ -1,9 +1,8 @@
 package projectile
 
 import (
-	"math/rand"
-
 	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
 )
 
 const (
@@ -17,39 +16,64 @@ type Shooter interface {
 }
 
 type Projectile struct {
-	ID       int
-	Pos      rl.Vector2
-	Velocity rl.Vector2
+	ecs.Entity
+	ecs.Transform
+	ecs.Velocity
+	ecs.Damage
+	ecs.Team
+	ecs.Lifetime
 
 	Radius float32
-	Damage float32
 
+	// Shooter earns exp for kills, enemy projectiles don't have one
 	Shooter Shooter
-
-	Expired bool
 }
 
 func FromPos(pos, velocity rl.Vector2, shooter Shooter) *Projectile {
-	velocity = rl.Vector2Normalize(velocity)
-	velocity = rl.Vector2Scale(velocity, initialSpeed)
 	return &Projectile{
-		ID:       rand.Int(),
-		Pos:      pos,
-		Velocity: velocity,
+		Entity:    ecs.NewEntity(),
+		Transform: ecs.Transform{Pos: pos, PrevPos: pos},
+		Velocity:  ecs.Velocity{Dir: rl.Vector2Normalize(velocity), Speed: initialSpeed},
+		Damage:    ecs.Damage{Amount: initialDamage},
+		Team:      ecs.TeamSoldiers,
 
 		Radius: initialRadius,
-		Damage: initialDamage,
 
 		Shooter: shooter,
 	}
 }
 
-func (p *Projectile) Move() {
-	p.Pos = rl.Vector2Add(p.Pos, p.Velocity)
+// FromEnemy creates projectile that damages soldiers
+func FromEnemy(pos, velocity rl.Vector2, speed, damage float32) *Projectile {
+	return &Projectile{
+		Entity:    ecs.NewEntity(),
+		Transform: ecs.Transform{Pos: pos, PrevPos: pos},
+		Velocity:  ecs.Velocity{Dir: rl.Vector2Normalize(velocity), Speed: speed},
+		Damage:    ecs.Damage{Amount: damage},
+		Team:      ecs.TeamEnemies,
+
+		Radius: initialRadius,
+	}
+}
+
+// Hits reports if projectile damages members of the team
+func (p *Projectile) Hits(team ecs.Team) bool {
+	return p.Team != team
+}
+
+// Credit gives exp for the kill to the shooter, if there is one
+func (p *Projectile) Credit(exp int) {
+	if p.Shooter != nil {
+		p.Shooter.EarnExp(exp)
+	}
 }
 
 func (p *Projectile) Draw() {
-	rl.DrawCircle(int32(p.Pos.X), int32(p.Pos.Y), p.Radius, rl.Red)
+	color := rl.Red
+	if p.Team == ecs.TeamEnemies {
+		color = rl.Green
+	}
+	rl.DrawCircle(int32(p.Pos.X), int32(p.Pos.Y), p.Radius, color)
 }
 
 func (p *Projectile) Boundaries() rl.Rectangle {
@@ -60,7 +84,3 @@ func (p *Projectile) Boundaries() rl.Rectangle {
 		Height: p.Radius * 2,
 	}
 }
-
-func (p *Projectile) GetPos() rl.Vector2 {
-	return p.Pos
-}
@@ -1,25 +1,16 @@
 package soldier
 
 import (
-	"math/rand"
-
 	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
+	"github.com/pechorka/illuminate-game-jam/internal/effects"
+	"github.com/pechorka/illuminate-game-jam/internal/events"
 	"github.com/pechorka/illuminate-game-jam/pkg/rlutils"
 )
 
 const (
-	initialSpeed            = 2
-	initialHealth           = 100
-	initialDamage           = 10
-	initialShootingRange    = 100
-	initialShootingRate     = 0.5
-	initialState            = Standing
-	initialLevelUpThreshold = 30
-)
-
-const (
-	nextLevelThreshold = 30 // percent more than previous level
-	statUp             = 10 // increase by percent when leveling up
+	initialState = Standing
 )
 
 const (
@@ -35,82 +26,126 @@ const (
 	Shooting
 )
 
+// Target is something soldier aims at
+type Target interface {
+	GetPos() rl.Vector2
+}
+
 type Soldier struct {
-	ID    int
-	Pos   rl.Vector2
-	State State
-
-	Speed         float32
-	MaxHealth     float32
-	Health        float32
-	Damage        float32
+	ecs.Entity
+	ecs.Transform
+	ecs.Velocity
+	ecs.Health
+	ecs.Damage
+	ecs.Sprite
+	ecs.Team
+	effects.Status
+
+	State  State
+	Target Target
+
 	ShootingRange float32
 	ShootingRate  float32
 
-	Walking rl.Texture2D
 	Levelup rl.Texture2D
 
 	ShootAgo float32
+	Level    int
+
+	events *events.Bus
+	stats  balance.Soldier
 
 	exp                  int
 	levelUpThreshold     int
 	levelupAnimationTime float32
 }
 
-func FromPos(pos rl.Vector2, walking, levelup rl.Texture2D) *Soldier {
+// FromPos creates soldier that publishes his events to bus, bus can be nil
+func FromPos(pos rl.Vector2, walking, levelup rl.Texture2D, stats balance.Soldier, bus *events.Bus) *Soldier {
 	return &Soldier{
-		ID:    rand.Int(),
-		Pos:   pos,
+		Entity:    ecs.NewEntity(),
+		Transform: ecs.Transform{Pos: pos, PrevPos: pos},
+		Velocity:  ecs.Velocity{Speed: stats.Speed},
+		Health:    ecs.NewHealth(stats.Health),
+		Damage:    ecs.Damage{Amount: stats.Damage},
+		Sprite:    ecs.Sprite{Texture: walking},
+		Team:      ecs.TeamSoldiers,
+
 		State: initialState,
 
-		Speed:         initialSpeed,
-		Health:        initialHealth,
-		MaxHealth:     initialHealth,
-		Damage:        initialDamage,
-		ShootingRange: initialShootingRange,
-		ShootingRate:  initialShootingRate,
+		ShootingRange: stats.ShootingRange,
+		ShootingRate:  stats.ShootingRate,
 
-		Walking: walking,
 		Levelup: levelup,
 
-		ShootAgo:         initialShootingRate,
-		levelUpThreshold: initialLevelUpThreshold,
+		ShootAgo: stats.ShootingRate,
+		Level:    1,
+
+		events: bus,
+		stats:  stats,
+
+		levelUpThreshold: stats.LevelUpThreshold,
 	}
 }
 
 func (s *Soldier) EarnExp(exp int) {
 	s.exp += exp
 	if s.exp >= s.levelUpThreshold {
-		s.levelUpThreshold = s.levelUpThreshold + s.levelUpThreshold*nextLevelThreshold/100
+		s.levelUpThreshold = s.levelUpThreshold + s.levelUpThreshold*s.stats.NextLevelThreshold/100
 		s.levelUp()
 	}
 }
 
 func (s *Soldier) levelUp() {
-	s.MaxHealth += s.MaxHealth * statUp / 100
-	s.Health = s.MaxHealth
-	s.Damage += s.Damage * statUp / 100
+	statUp := float32(s.stats.StatUp)
+	s.MaxHP += s.MaxHP * statUp / 100
+	s.HP = s.MaxHP
+	s.Amount += s.Amount * statUp / 100
 	s.ShootingRange += s.ShootingRange * statUp / 100
 	s.ShootingRate -= s.ShootingRate * statUp / 100
 	s.levelupAnimationTime = 1
+	s.Level++
+
+	events.Publish(s.events, events.SoldierLeveledUp{
+		SoldierID: s.ID,
+		Level:     s.Level,
+	})
+}
+
+// Rebalance applies new stats keeping level ups and health ratio
+func (s *Soldier) Rebalance(next balance.Soldier) {
+	prev := s.stats
+	scale := func(value, prev, next float32) float32 {
+		if prev == 0 {
+			return value
+		}
+		return value * next / prev
+	}
+	s.Speed = scale(s.Speed, prev.Speed, next.Speed)
+	s.HP = scale(s.HP, prev.Health, next.Health)
+	s.MaxHP = scale(s.MaxHP, prev.Health, next.Health)
+	s.Amount = scale(s.Amount, prev.Damage, next.Damage)
+	s.ShootingRange = scale(s.ShootingRange, prev.ShootingRange, next.ShootingRange)
+	s.ShootingRate = scale(s.ShootingRate, prev.ShootingRate, next.ShootingRate)
+	s.stats = next
 }
 
 func (s *Soldier) Draw() {
-	texture := s.Walking
+	texture := s.Texture
 	switch s.State {
 	case Standing:
-		texture = s.Walking
+		texture = s.Texture
 	case Melee:
-		texture = s.Walking // TODO: change to shooting texture
+		texture = s.Texture // TODO: change to shooting texture
 	case Shooting:
-		texture = s.Walking // TODO: change to shooting texture
+		texture = s.Texture // TODO: change to shooting texture
 	}
 	// draw health bar above soldier
 	// +2 and +1 are to make the health bar look better
-	scaledDownInitialHealth := scaleToWidth(s.MaxHealth, s.MaxHealth, healthbarWidth) + 4
+	scaledDownInitialHealth := scaleToWidth(s.MaxHP, s.MaxHP, healthbarWidth) + 4
 	healthbarBorder := rl.NewRectangle(s.Pos.X, s.Pos.Y-10, scaledDownInitialHealth, healthbarHeight)
 	rl.DrawRectangleLinesEx(healthbarBorder, 2, rl.White)
-	scaledDownCurrentHealth := scaleToWidth(s.Health, s.MaxHealth, healthbarWidth)
+	scaledDownCurrentHealth := scaleToWidth(s.HP, s.MaxHP, healthbarWidth)
 	healthbar := rl.Rectangle{
 		X:      s.Pos.X + 2,
 		Y:      s.Pos.Y - 8,
@@ -124,8 +159,10 @@ func (s *Soldier) Draw() {
 		rl.DrawTexture(s.Levelup, int32(levelUpPosition.X), int32(levelUpPosition.Y), rl.White)
 	}
 
+	s.DrawEffects(healthbarBorder)
+
 	// draw circle with radius of shooting range
-	rl.DrawCircleLines(int32(s.Pos.X), int32(s.Pos.Y), s.ShootingRange, rl.White)
+	rl.DrawCircleLines(int32(s.Pos.X), int32(s.Pos.Y), s.Range(), rl.White)
 
 	rl.DrawTexture(texture, int32(s.Pos.X), int32(s.Pos.Y), rl.White)
 }
@@ -142,11 +179,19 @@ func (s *Soldier) MoveTowards(pos rl.Vector2) rl.Vector2 {
 	return rl.Vector2Add(s.Pos, dir)
 }
 
+// Range is shooting range reduced by blinds
+func (s *Soldier) Range() float32 {
+	return s.ShootingRange * s.RangeMultiplier()
+}
+
 func (s *Soldier) WithinShootingRange(pos rl.Vector2) bool {
-	return rl.Vector2Distance(s.Pos, pos) < s.ShootingRange
+	return rl.Vector2Distance(s.Pos, pos) < s.Range()
 }
 
 func (s *Soldier) CanShoot(shootFast bool) bool {
+	if s.Stunned() {
+		return false
+	}
 	rate := s.ShootingRate
 	if shootFast {
 		rate = rate / 2
@@ -158,19 +203,16 @@ func (s *Soldier) Shoot() {
 	s.ShootAgo = 0
 }
 
+// ProgressTime reloads the gun, slowed soldiers reload slower and stunned ones don't reload at all
 func (s *Soldier) ProgressTime(dt float32) {
 	if s.ShootAgo < s.ShootingRate {
-		s.ShootAgo += dt
+		s.ShootAgo += dt * s.SpeedMultiplier()
 	}
 	if s.levelupAnimationTime > 0 {
 		s.levelupAnimationTime -= dt
 	}
 }
 
-func (s *Soldier) GetPos() rl.Vector2 {
-	return s.Pos
-}
-
 func (s *Soldier) Boundaries() rl.Rectangle {
-	return rlutils.TextureBoundaries(s.Walking, s.Pos)
+	return s.BoundariesAt(s.Pos)
 }
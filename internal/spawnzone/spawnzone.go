@@ -0,0 +1,167 @@
+// Package spawnzone contains parts of the arena enemies come from
+// and telegraphs that mark where they are about to appear.
+package spawnzone
+
+import (
+	"math"
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/pkg/rlutils"
+)
+
+type Kind int
+
+const (
+	Edge Kind = iota + 1
+	Corner
+	Nest
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Edge:
+		return "edge"
+	case Corner:
+		return "corner"
+	case Nest:
+		return "nest"
+	}
+	return "unknown"
+}
+
+// Any matches every zone
+const Any = "any"
+
+// Names are everything zone can be matched by: kinds, sides of edges and corners, and Any
+var Names = []string{
+	Any, "edge", "corner", "nest",
+	"top", "bottom", "left", "right",
+	"top left", "top right", "bottom left", "bottom right",
+}
+
+type Zone struct {
+	Kind   Kind
+	Side   string // top, bottom, left or right for edges, top left and so on for corners, empty for nests
+	Bounds rl.Rectangle
+
+	arena rl.Rectangle
+}
+
+// Layout returns edges and corners of arena that are depth pixels deep, and nests
+func Layout(arena rl.Rectangle, depth float32, nests []rl.Rectangle) []Zone {
+	right := arena.X + arena.Width - depth
+	bottom := arena.Y + arena.Height - depth
+	zones := []Zone{
+		{Kind: Edge, Side: "top", Bounds: rl.Rectangle{X: arena.X, Y: arena.Y, Width: arena.Width, Height: depth}},
+		{Kind: Edge, Side: "bottom", Bounds: rl.Rectangle{X: arena.X, Y: bottom, Width: arena.Width, Height: depth}},
+		{Kind: Edge, Side: "left", Bounds: rl.Rectangle{X: arena.X, Y: arena.Y, Width: depth, Height: arena.Height}},
+		{Kind: Edge, Side: "right", Bounds: rl.Rectangle{X: right, Y: arena.Y, Width: depth, Height: arena.Height}},
+		{Kind: Corner, Side: "top left", Bounds: rl.Rectangle{X: arena.X, Y: arena.Y, Width: depth, Height: depth}},
+		{Kind: Corner, Side: "top right", Bounds: rl.Rectangle{X: right, Y: arena.Y, Width: depth, Height: depth}},
+		{Kind: Corner, Side: "bottom left", Bounds: rl.Rectangle{X: arena.X, Y: bottom, Width: depth, Height: depth}},
+		{Kind: Corner, Side: "bottom right", Bounds: rl.Rectangle{X: right, Y: bottom, Width: depth, Height: depth}},
+	}
+	for _, n := range nests {
+		zones = append(zones, Zone{Kind: Nest, Bounds: n})
+	}
+	for i := range zones {
+		zones[i].arena = arena
+	}
+	return zones
+}
+
+// Matches reports whether zone has given name, see Names
+func (z Zone) Matches(name string) bool {
+	return name == Any || name == z.Kind.String() || name == z.Side
+}
+
+// RandomPos returns top left corner of a size by size square in the zone.
+// Square that is bigger than the zone sticks out of it, but stays in the arena.
+func (z Zone) RandomPos(size float32) rl.Vector2 {
+	x := rlutils.RandomFloat(z.Bounds.X, max(z.Bounds.X, z.Bounds.X+z.Bounds.Width-size))
+	y := rlutils.RandomFloat(z.Bounds.Y, max(z.Bounds.Y, z.Bounds.Y+z.Bounds.Height-size))
+	return rl.Vector2{
+		X: max(z.arena.X, min(x, z.arena.X+z.arena.Width-size)),
+		Y: max(z.arena.Y, min(y, z.arena.Y+z.arena.Height-size)),
+	}
+}
+
+var (
+	nestColor     = rl.Color{R: 45, G: 30, B: 30, A: 255}
+	nestEdgeColor = rl.Color{R: 80, G: 40, B: 35, A: 255}
+)
+
+// DrawNest draws zone if it's a nest, edges and corners are not marked
+func (z Zone) DrawNest() {
+	if z.Kind != Nest {
+		return
+	}
+	rl.DrawRectangleRounded(z.Bounds, 0.6, 8, nestColor)
+	rl.DrawRectangleRoundedLines(z.Bounds, 0.6, 8, 2, nestEdgeColor)
+}
+
+// Weight of zone in director's weights
+func Weight(z Zone, w balance.ZoneWeights) float32 {
+	switch z.Kind {
+	case Edge:
+		return w.Edge
+	case Corner:
+		return w.Corner
+	case Nest:
+		return w.Nest
+	}
+	return 0
+}
+
+// Pick chooses zone by weight using roll in [0, 1), false if every weight is zero
+func Pick(zones []Zone, weight func(Zone) float32, roll float32) (Zone, bool) {
+	weights := make([]float32, len(zones))
+	for i, z := range zones {
+		weights[i] = weight(z)
+	}
+	i, ok := rlutils.PickWeighted(weights, roll)
+	if !ok {
+		return Zone{}, false
+	}
+	return zones[i], true
+}
+
+// Roll picks random zone by weight
+func Roll(zones []Zone, weight func(Zone) float32) (Zone, bool) {
+	return Pick(zones, weight, rand.Float32())
+}
+
+// TelegraphDuration is seconds between marking a place and enemies appearing there
+const TelegraphDuration = 0.5
+
+var telegraphColor = rl.Red
+
+// Telegraph marks area where enemies are about to spawn, so players can react
+type Telegraph struct {
+	Area rl.Rectangle
+	Left float32 // seconds until spawn
+}
+
+func NewTelegraph(area rl.Rectangle) Telegraph {
+	return Telegraph{Area: area, Left: TelegraphDuration}
+}
+
+func (t *Telegraph) Progress(dt float32) {
+	t.Left = max(0, t.Left-dt)
+}
+
+func (t *Telegraph) Ready() bool {
+	return t.Left <= 0
+}
+
+// Draw pulses faster as spawn gets closer
+func (t *Telegraph) Draw() {
+	passed := float64(TelegraphDuration - t.Left)
+	pulse := float32(0.5 + 0.5*math.Sin(passed*passed*80))
+	center := rlutils.RectangleCenter(t.Area)
+	radius := max(t.Area.Width, t.Area.Height) / 2
+	rl.DrawCircleV(center, radius, rl.Fade(telegraphColor, 0.15+0.25*pulse))
+	rl.DrawCircleLines(int32(center.X), int32(center.Y), radius, rl.Fade(telegraphColor, 0.5+0.5*pulse))
+}
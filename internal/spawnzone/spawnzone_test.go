@@ -0,0 +1,101 @@
+package spawnzone
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+var testArena = rl.Rectangle{X: 0, Y: 50, Width: 800, Height: 600}
+
+func TestLayout(t *testing.T) {
+	nest := rl.Rectangle{X: 300, Y: 300, Width: 90, Height: 90}
+	zones := Layout(testArena, 100, []rl.Rectangle{nest})
+
+	kinds := map[Kind]int{}
+	for _, z := range zones {
+		kinds[z.Kind]++
+	}
+	if kinds[Edge] != 4 || kinds[Corner] != 4 || kinds[Nest] != 1 {
+		t.Errorf("got zones %v, want 4 edges, 4 corners and a nest", kinds)
+	}
+
+	t.Run("random position stays in zone", func(t *testing.T) {
+		for _, z := range zones {
+			for range 20 {
+				pos := z.RandomPos(40)
+				square := rl.Rectangle{X: pos.X, Y: pos.Y, Width: 40, Height: 40}
+				if !rl.CheckCollisionPointRec(pos, z.Bounds) || square.X+40 > z.Bounds.X+z.Bounds.Width {
+					t.Fatalf("got %v, want it in %s zone %v", square, z.Kind, z.Bounds)
+				}
+			}
+		}
+	})
+
+	t.Run("big square stays in arena", func(t *testing.T) {
+		for _, z := range zones {
+			pos := z.RandomPos(150)
+			if pos.X < testArena.X || pos.Y < testArena.Y ||
+				pos.X+150 > testArena.X+testArena.Width || pos.Y+150 > testArena.Y+testArena.Height {
+				t.Fatalf("got %v in %s zone, want square to fit arena", pos, z.Side)
+			}
+		}
+	})
+}
+
+func TestMatches(t *testing.T) {
+	zones := Layout(testArena, 100, []rl.Rectangle{{X: 300, Y: 300, Width: 90, Height: 90}})
+	count := func(name string) int {
+		n := 0
+		for _, z := range zones {
+			if z.Matches(name) {
+				n++
+			}
+		}
+		return n
+	}
+
+	for name, want := range map[string]int{Any: 9, "edge": 4, "corner": 4, "nest": 1, "left": 1, "top right": 1} {
+		if got := count(name); got != want {
+			t.Errorf("%s: got %d zones, want %d", name, got, want)
+		}
+	}
+}
+
+func TestPick(t *testing.T) {
+	zones := Layout(testArena, 100, []rl.Rectangle{{X: 300, Y: 300, Width: 90, Height: 90}})
+	weights := balance.ZoneWeights{Corner: 1, Nest: 4}
+	weight := func(z Zone) float32 { return Weight(z, weights) }
+
+	t.Run("by weight", func(t *testing.T) {
+		if z, _ := Pick(zones, weight, 0); z.Side != "top left" {
+			t.Errorf("got %s %s, want first corner", z.Kind, z.Side)
+		}
+		if z, _ := Pick(zones, weight, 0.51); z.Kind != Nest {
+			t.Errorf("got %s, want nest", z.Kind)
+		}
+		if z, _ := Pick(zones, weight, 0.9999999); z.Kind != Nest {
+			t.Errorf("got %s, want nest", z.Kind)
+		}
+	})
+
+	t.Run("nothing to pick", func(t *testing.T) {
+		if _, ok := Pick(zones, func(Zone) float32 { return 0 }, 0.5); ok {
+			t.Errorf("got zone, want none")
+		}
+	})
+}
+
+func TestTelegraph(t *testing.T) {
+	tg := NewTelegraph(rl.Rectangle{Width: 10, Height: 10})
+
+	tg.Progress(TelegraphDuration / 2)
+	if tg.Ready() {
+		t.Fatalf("ready with %vs left", tg.Left)
+	}
+	tg.Progress(TelegraphDuration)
+	if !tg.Ready() || tg.Left != 0 {
+		t.Errorf("got %vs left, want ready", tg.Left)
+	}
+}
@@ -0,0 +1,69 @@
+// Package stats collects run statistics from game events
+package stats
+
+import "github.com/pechorka/illuminate-game-jam/internal/events"
+
+type Stats struct {
+	Kills           int
+	DamageTaken     float32
+	LevelUps        int
+	SoldiersLost    int
+	MoneySpent      int
+	ConsumablesUsed map[string]int
+	VIPsEscorted    int
+	VIPsLost        int
+
+	ContractsSigned    int
+	ContractsCompleted map[string]int
+}
+
+// Subscribe returns stats that are updated by events published to bus.
+// Stats are reset when new run starts.
+func Subscribe(bus *events.Bus) *Stats {
+	s := &Stats{}
+	s.reset()
+
+	events.Subscribe(bus, func(events.RunStarted) {
+		s.reset()
+	})
+	events.Subscribe(bus, func(events.EnemyKilled) {
+		s.Kills++
+	})
+	events.Subscribe(bus, func(e events.SoldierDamaged) {
+		s.DamageTaken += e.Damage
+	})
+	events.Subscribe(bus, func(events.SoldierLeveledUp) {
+		s.LevelUps++
+	})
+	events.Subscribe(bus, func(events.SoldierDied) {
+		s.SoldiersLost++
+	})
+	events.Subscribe(bus, func(e events.ItemBought) {
+		s.MoneySpent += e.Price
+	})
+	events.Subscribe(bus, func(e events.ConsumableUsed) {
+		s.ConsumablesUsed[e.Name]++
+	})
+	events.Subscribe(bus, func(e events.EscortEnded) {
+		if e.Escorted {
+			s.VIPsEscorted++
+		} else {
+			s.VIPsLost++
+		}
+	})
+	events.Subscribe(bus, func(events.ContractSigned) {
+		s.ContractsSigned++
+	})
+	events.Subscribe(bus, func(e events.ContractCompleted) {
+		s.ContractsCompleted[e.Name]++
+	})
+
+	return s
+}
+
+func (s *Stats) reset() {
+	*s = Stats{
+		ConsumablesUsed:    make(map[string]int),
+		ContractsCompleted: make(map[string]int),
+	}
+}
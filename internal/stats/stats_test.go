@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/pechorka/illuminate-game-jam/internal/events"
+)
+
+func TestSubscribe(t *testing.T) {
+	bus := events.NewBus()
+	s := Subscribe(bus)
+
+	events.Publish(bus, events.RunStarted{Soldiers: 2})
+	events.Publish(bus, events.EnemyKilled{Reward: 5})
+	events.Publish(bus, events.EnemyKilled{Reward: 7})
+	events.Publish(bus, events.SoldierDamaged{Damage: 3})
+	events.Publish(bus, events.SoldierDamaged{Damage: 4.5})
+	events.Publish(bus, events.ItemBought{Name: "Flare", Price: 10})
+	events.Publish(bus, events.ConsumableUsed{Name: "Flare"})
+	events.Publish(bus, events.ConsumableUsed{Name: "Flare"})
+	events.Publish(bus, events.SoldierLeveledUp{Level: 2})
+	events.Publish(bus, events.SoldierDied{})
+	events.Publish(bus, events.EscortEnded{Escorted: true, Money: 400})
+	events.Publish(bus, events.ContractSigned{Name: "Frenzy"})
+	events.Publish(bus, events.ContractSigned{Name: "Blackout"})
+	events.Publish(bus, events.ContractCompleted{Name: "Frenzy", Cash: 150, Score: 300})
+
+	if s.Kills != 2 {
+		t.Errorf("got %d kills, want 2", s.Kills)
+	}
+	if s.DamageTaken != 7.5 {
+		t.Errorf("got %v damage taken, want 7.5", s.DamageTaken)
+	}
+	if s.MoneySpent != 10 {
+		t.Errorf("got %d money spent, want 10", s.MoneySpent)
+	}
+	if s.ConsumablesUsed["Flare"] != 2 {
+		t.Errorf("got %d flares used, want 2", s.ConsumablesUsed["Flare"])
+	}
+	if s.LevelUps != 1 || s.SoldiersLost != 1 {
+		t.Errorf("got %d level ups and %d soldiers lost, want 1 and 1", s.LevelUps, s.SoldiersLost)
+	}
+
+	if s.VIPsEscorted != 1 || s.VIPsLost != 0 {
+		t.Errorf("got %d VIPs escorted and %d lost, want 1 and 0", s.VIPsEscorted, s.VIPsLost)
+	}
+	if s.ContractsSigned != 2 || s.ContractsCompleted["Frenzy"] != 1 {
+		t.Errorf("got %d contracts signed and %v completed, want 2 and a frenzy", s.ContractsSigned, s.ContractsCompleted)
+	}
+
+	events.Publish(bus, events.RunStarted{Soldiers: 1})
+	if s.Kills != 0 || len(s.ConsumablesUsed) != 0 || len(s.ContractsCompleted) != 0 {
+		t.Errorf("stats weren't reset on new run")
+	}
+}
@@ -0,0 +1,93 @@
+// Package steering contains steering behaviours that are blended into enemy movement.
+//
+// Every behaviour returns a force in pixels per frame, forces are weighted,
+// summed and limited by speed, so enemy never moves faster than it can.
+package steering
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+// Forces acting on entity this frame
+type Forces struct {
+	Seek       rl.Vector2
+	Flee       rl.Vector2
+	Separation rl.Vector2
+}
+
+// Blend sums forces with their weights, result is not longer than speed
+func (f Forces) Blend(w balance.Steering, speed float32) rl.Vector2 {
+	v := rl.Vector2Scale(f.Seek, w.Seek)
+	v = rl.Vector2Add(v, rl.Vector2Scale(f.Flee, w.Flee))
+	v = rl.Vector2Add(v, rl.Vector2Scale(f.Separation, w.Separation))
+	return Limit(v, speed)
+}
+
+// Arrive goes to target with full speed and slows down within arrival radius
+func Arrive(pos, target rl.Vector2, speed, arrival float32) rl.Vector2 {
+	offset := rl.Vector2Subtract(target, pos)
+	dist := rl.Vector2Length(offset)
+	if dist == 0 {
+		return rl.Vector2{}
+	}
+	if dist < arrival {
+		speed *= dist / arrival
+	}
+	return rl.Vector2Scale(offset, speed/dist)
+}
+
+// Flee pushes away from threat with full speed at its center, force weakens to zero at radius
+func Flee(pos, threat rl.Vector2, speed, radius float32) rl.Vector2 {
+	offset := rl.Vector2Subtract(pos, threat)
+	dist := rl.Vector2Length(offset)
+	if dist >= radius {
+		return rl.Vector2{}
+	}
+	strength := speed * (1 - dist/radius)
+	if dist == 0 {
+		// any direction is fine, as long as it's away
+		return rl.Vector2{X: strength}
+	}
+	return rl.Vector2Scale(offset, strength/dist)
+}
+
+// Neighbour is another entity to keep distance from
+type Neighbour struct {
+	ID  int
+	Pos rl.Vector2
+}
+
+// Separate pushes self away from neighbours closer than radius, the closer neighbour the stronger push.
+// Neighbours at the same position are pushed apart along X axis, lower ID goes left.
+func Separate(self Neighbour, neighbours []Neighbour, speed, radius float32) rl.Vector2 {
+	var push rl.Vector2
+	for _, n := range neighbours {
+		if n.ID == self.ID {
+			continue
+		}
+		offset := rl.Vector2Subtract(self.Pos, n.Pos)
+		dist := rl.Vector2Length(offset)
+		if dist >= radius {
+			continue
+		}
+		dir := rl.Vector2{X: 1}
+		switch {
+		case dist > 0:
+			dir = rl.Vector2Scale(offset, 1/dist)
+		case self.ID < n.ID:
+			dir = rl.Vector2{X: -1}
+		}
+		push = rl.Vector2Add(push, rl.Vector2Scale(dir, 1-dist/radius))
+	}
+	return Limit(rl.Vector2Scale(push, speed), speed)
+}
+
+// Limit shortens v to max length
+func Limit(v rl.Vector2, max float32) rl.Vector2 {
+	length := rl.Vector2Length(v)
+	if length <= max || length == 0 {
+		return v
+	}
+	return rl.Vector2Scale(v, max/length)
+}
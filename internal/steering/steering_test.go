@@ -0,0 +1,91 @@
+package steering
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+)
+
+func TestArrive(t *testing.T) {
+	cases := []struct {
+		name   string
+		target rl.Vector2
+		want   rl.Vector2
+	}{
+		{name: "far target full speed", target: rl.Vector2{X: 100}, want: rl.Vector2{X: 2}},
+		{name: "slows down within arrival", target: rl.Vector2{X: 5}, want: rl.Vector2{X: 1}},
+		{name: "stops at target", target: rl.Vector2{}, want: rl.Vector2{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Arrive(rl.Vector2{}, tc.target, 2, 10)
+			if got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFlee(t *testing.T) {
+	threat := rl.Vector2{X: 100, Y: 100}
+	cases := []struct {
+		name string
+		pos  rl.Vector2
+		want rl.Vector2
+	}{
+		{name: "outside radius", pos: rl.Vector2{X: 160, Y: 100}, want: rl.Vector2{}},
+		{name: "half way", pos: rl.Vector2{X: 125, Y: 100}, want: rl.Vector2{X: 1}},
+		{name: "near center", pos: rl.Vector2{X: 100, Y: 95}, want: rl.Vector2{Y: -1.8}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Flee(tc.pos, threat, 2, 50)
+			if rl.Vector2Distance(got, tc.want) > 1e-5 {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSeparate(t *testing.T) {
+	t.Run("pushes away from close neighbours only", func(t *testing.T) {
+		self := Neighbour{ID: 1, Pos: rl.Vector2{X: 100, Y: 100}}
+		neighbours := []Neighbour{
+			self,
+			{ID: 2, Pos: rl.Vector2{X: 90, Y: 100}},
+			{ID: 3, Pos: rl.Vector2{X: 100, Y: 200}},
+		}
+
+		got := Separate(self, neighbours, 2, 20)
+
+		if want := (rl.Vector2{X: 1}); got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("neighbours at the same position go different ways", func(t *testing.T) {
+		a := Neighbour{ID: 1, Pos: rl.Vector2{X: 100, Y: 100}}
+		b := Neighbour{ID: 2, Pos: a.Pos}
+
+		pushA := Separate(a, []Neighbour{b}, 2, 20)
+		pushB := Separate(b, []Neighbour{a}, 2, 20)
+
+		if pushA.X >= 0 || pushB.X <= 0 {
+			t.Errorf("got pushes %v and %v, want opposite along X", pushA, pushB)
+		}
+	})
+}
+
+func TestBlend(t *testing.T) {
+	forces := Forces{
+		Seek: rl.Vector2{X: 1},
+		Flee: rl.Vector2{X: -1},
+	}
+
+	got := forces.Blend(balance.Steering{Seek: 1, Flee: 3}, 1)
+
+	if want := (rl.Vector2{X: -1}); got != want {
+		t.Errorf("got %v, want %v, flee should win and be limited by speed", got, want)
+	}
+}
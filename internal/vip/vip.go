@@ -0,0 +1,89 @@
+// Package vip contains VIP of escort contract: it can't fight, wanders around the arena
+// and enemies go for it before soldiers.
+package vip
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
+	"github.com/pechorka/illuminate-game-jam/pkg/rlutils"
+)
+
+const (
+	healthbarWidth  float32 = 40
+	healthbarHeight float32 = 8
+	// waypointReach is how close VIP has to get to waypoint to pick the next one
+	waypointReach float32 = 5
+)
+
+type VIP struct {
+	ecs.Entity
+	ecs.Transform
+	ecs.Velocity
+	ecs.Health
+	ecs.Sprite
+	ecs.Team
+
+	Waypoint rl.Vector2 // where VIP wanders to
+	Left     float32    // seconds until VIP is escorted
+}
+
+func FromPos(pos rl.Vector2, texture rl.Texture2D, stats balance.Escort) *VIP {
+	return &VIP{
+		Entity:    ecs.NewEntity(),
+		Transform: ecs.Transform{Pos: pos, PrevPos: pos},
+		Velocity:  ecs.Velocity{Speed: stats.Speed},
+		Health:    ecs.NewHealth(stats.Health),
+		Sprite:    ecs.Sprite{Texture: texture},
+		Team:      ecs.TeamSoldiers,
+
+		Waypoint: pos,
+		Left:     stats.Duration,
+	}
+}
+
+func (v *VIP) Boundaries() rl.Rectangle {
+	return v.BoundariesAt(v.Pos)
+}
+
+func (v *VIP) ProgressTime(dt float32) {
+	v.Left = max(0, v.Left-dt)
+}
+
+// Escorted reports whether VIP survived until the end of contract
+func (v *VIP) Escorted() bool {
+	return v.Left <= 0 && !v.IsDead()
+}
+
+// Move returns step towards waypoint, false once waypoint is reached and VIP needs a new one
+func (v *VIP) Move() (rl.Vector2, bool) {
+	toWaypoint := rl.Vector2Subtract(v.Waypoint, v.Pos)
+	dist := rl.Vector2Length(toWaypoint)
+	if dist <= waypointReach {
+		return rl.Vector2{}, false
+	}
+	return rl.Vector2Scale(toWaypoint, min(v.Speed, dist)/dist), true
+}
+
+func (v *VIP) Draw() {
+	center := rlutils.RectangleCenter(v.Boundaries())
+	rl.DrawCircleLines(int32(center.X), int32(center.Y), float32(max(v.Texture.Width, v.Texture.Height)), rl.Gold)
+
+	barX := center.X - healthbarWidth/2
+	border := rl.NewRectangle(barX-2, v.Pos.Y-12, healthbarWidth+4, healthbarHeight)
+	rl.DrawRectangleLinesEx(border, 2, rl.Gold)
+	health := rl.Rectangle{
+		X:      barX,
+		Y:      v.Pos.Y - 10,
+		Width:  rlutils.ScaleValueToSize(max(v.HP, 0), 0, v.MaxHP, 0, healthbarWidth),
+		Height: healthbarHeight - 4,
+	}
+	rl.DrawRectangleRec(health, rl.Green)
+
+	timer := fmt.Sprintf("%ds", int(v.Left+0.999))
+	rl.DrawText(timer, int32(center.X)-rl.MeasureText(timer, 10)/2, int32(v.Pos.Y)-24, 10, rl.Gold)
+
+	v.DrawAt(v.Pos)
+}
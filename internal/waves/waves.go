@@ -0,0 +1,242 @@
+// Package waves contains scripted waves of wave mode.
+//
+// Default waves are embedded into the binary, a wave file replaces them as a whole.
+package waves
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/pechorka/illuminate-game-jam/internal/spawnzone"
+)
+
+// Group is enemies of one type that come one by one
+type Group struct {
+	Enemy    string             `json:"enemy"`    // name of enemy in balance file or boss
+	Count    int                `json:"count"`    // pack enemies come in whole packs
+	Zones    map[string]float32 `json:"zones"`    // weights of spawn zones by name, every zone is equally likely if empty
+	Start    float32            `json:"start"`    // seconds since the start of the wave
+	Interval float32            `json:"interval"` // seconds between enemies
+}
+
+// ZoneWeight is how likely enemies of the group come from zone
+func (g Group) ZoneWeight(z spawnzone.Zone) float32 {
+	if len(g.Zones) == 0 {
+		return 1
+	}
+	var weight float32
+	for name, w := range g.Zones {
+		if z.Matches(name) {
+			weight += w
+		}
+	}
+	return weight
+}
+
+type Wave struct {
+	Groups []Group `json:"groups"`
+	Break  float32 `json:"break"` // seconds of shopping after the wave is cleared
+}
+
+type File struct {
+	Waves []Wave `json:"waves"`
+}
+
+// Load parses wave file at path, or defaults if there is no such file.
+// known reports whether enemy with given name exists.
+func Load(defaults []byte, path string, known func(enemy string) bool) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Parse(defaults, known)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("wave file: %w", err)
+	}
+	f, err := Parse(data, known)
+	if err != nil {
+		return nil, fmt.Errorf("wave file %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse parses and validates complete wave file
+func Parse(data []byte, known func(enemy string) bool) (*File, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var f File
+	if err := dec.Decode(&f); err != nil {
+		return nil, err
+	}
+	if err := f.Validate(known); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Validate returns all problems found in wave file, one per line
+func (f *File) Validate(known func(enemy string) bool) error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(len(f.Waves) > 0, "waves: at least one wave is required")
+	for i, w := range f.Waves {
+		path := fmt.Sprintf("waves[%d]", i)
+		check(len(w.Groups) > 0, "%s.groups: at least one group is required", path)
+		check(w.Break >= 0, "%s.break: %v can't be negative", path, w.Break)
+		for j, g := range w.Groups {
+			path := fmt.Sprintf("%s.groups[%d]", path, j)
+			check(known(g.Enemy), "%s.enemy: unknown enemy %q", path, g.Enemy)
+			check(g.Count > 0, "%s.count: %v must be positive", path, g.Count)
+			var total float32
+			for name, w := range g.Zones {
+				check(slices.Contains(spawnzone.Names, name), "%s.zones: unknown zone %q", path, name)
+				check(w >= 0, "%s.zones.%s: %v can't be negative", path, name, w)
+				total += w
+			}
+			check(len(g.Zones) == 0 || total > 0, "%s.zones: at least one weight must be positive", path)
+			check(g.Start >= 0, "%s.start: %v can't be negative", path, g.Start)
+			check(g.Interval >= 0, "%s.interval: %v can't be negative", path, g.Interval)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+type State int
+
+const (
+	Fighting State = iota + 1 // enemies of the wave come to the arena
+	Break                     // wave is cleared, shop is open until next wave
+	Done                      // the last wave is cleared
+)
+
+// Script runs waves one after another, breaks go between them
+type Script struct {
+	waves []Wave
+
+	state   State
+	wave    int     // index of current wave
+	time    float32 // seconds since current wave or break started
+	spawned []int   // enemies spawned of every group of current wave
+	due     int     // group which enemy is due to spawn, -1 if none
+}
+
+// NewScript starts the first wave
+func NewScript(f *File) *Script {
+	s := &Script{waves: f.Waves}
+	s.startWave(0)
+	return s
+}
+
+func (s *Script) startWave(i int) {
+	s.state = Fighting
+	s.wave = i
+	s.time = 0
+	s.spawned = make([]int, len(s.waves[i].Groups))
+	s.due = -1
+}
+
+func (s *Script) State() State {
+	return s.state
+}
+
+// Wave is the number of current wave, starting from 1
+func (s *Script) Wave() int {
+	return s.wave + 1
+}
+
+func (s *Script) Waves() int {
+	return len(s.waves)
+}
+
+// BreakLeft is seconds until next wave
+func (s *Script) BreakLeft() float32 {
+	if s.state != Break {
+		return 0
+	}
+	return max(0, s.waves[s.wave].Break-s.time)
+}
+
+// Remaining is how many enemies of current wave are yet to be killed.
+// packSize tells how many enemies come in one pack of given enemy.
+func (s *Script) Remaining(alive int, packSize func(enemy string) int) int {
+	if s.state != Fighting {
+		return 0
+	}
+	remaining := alive
+	for i, g := range s.waves[s.wave].Groups {
+		remaining += (g.Count - s.spawned[i]) * packSize(g.Enemy)
+	}
+	return remaining
+}
+
+// allSpawned reports whether every pack of current wave is spawned
+func (s *Script) allSpawned() bool {
+	for i, g := range s.waves[s.wave].Groups {
+		if s.spawned[i] < g.Count {
+			return false
+		}
+	}
+	return true
+}
+
+// Update moves script dt seconds forward. Wave is cleared once all its enemies
+// are spawned and none of alive enemies is left.
+func (s *Script) Update(dt float32, alive int) {
+	s.time += dt
+	switch s.state {
+	case Fighting:
+		if alive == 0 && s.allSpawned() {
+			if s.wave == len(s.waves)-1 {
+				s.state = Done
+				return
+			}
+			s.state = Break
+			s.time = 0
+		}
+	case Break:
+		if s.time >= s.waves[s.wave].Break {
+			s.startWave(s.wave + 1)
+		}
+	}
+}
+
+// SkipBreak starts next wave right away
+func (s *Script) SkipBreak() {
+	if s.state == Break {
+		s.startWave(s.wave + 1)
+	}
+}
+
+// Next returns group which enemy is due to spawn, false if nothing is due.
+// The same group is returned until Spawned is called.
+func (s *Script) Next() (Group, bool) {
+	if s.state != Fighting {
+		return Group{}, false
+	}
+	for i, g := range s.waves[s.wave].Groups {
+		if s.spawned[i] < g.Count && s.time >= g.Start+float32(s.spawned[i])*g.Interval {
+			s.due = i
+			return g, true
+		}
+	}
+	s.due = -1
+	return Group{}, false
+}
+
+// Spawned marks enemy of the group returned by Next as spawned
+func (s *Script) Spawned() {
+	if s.due >= 0 {
+		s.spawned[s.due]++
+		s.due = -1
+	}
+}
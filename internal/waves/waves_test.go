@@ -0,0 +1,174 @@
+package waves
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func knownEnemy(enemy string) bool {
+	return enemy == "basic" || enemy == "fast"
+}
+
+func TestParse(t *testing.T) {
+	t.Run("defaults are valid", func(t *testing.T) {
+		defaults, err := os.ReadFile("../../assets/waves.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		known := func(string) bool { return true }
+		if _, err := Parse(defaults, known); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("invalid waves", func(t *testing.T) {
+		data := `{"waves": [
+			{"groups": [{"enemy": "ghost", "count": 0, "zones": {"middle": 1, "top": -1}, "start": -1, "interval": 1}], "break": -5},
+			{"groups": [], "break": 0}
+		]}`
+
+		_, err := Parse([]byte(data), knownEnemy)
+		if err == nil {
+			t.Fatal("got no error")
+		}
+		for _, want := range []string{
+			`waves[0].groups[0].enemy: unknown enemy "ghost"`,
+			"waves[0].groups[0].count: 0 must be positive",
+			`waves[0].groups[0].zones: unknown zone "middle"`,
+			"waves[0].groups[0].zones.top: -1 can't be negative",
+			"waves[0].groups[0].zones: at least one weight must be positive",
+			"waves[0].groups[0].start: -1 can't be negative",
+			"waves[0].break: -5 can't be negative",
+			"waves[1].groups: at least one group is required",
+		} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("got error %q, want it to contain %q", err, want)
+			}
+		}
+	})
+}
+
+func newTestScript() *Script {
+	return NewScript(&File{Waves: []Wave{
+		{
+			Groups: []Group{
+				{Enemy: "basic", Count: 2, Zones: map[string]float32{"left": 1}, Start: 0, Interval: 1},
+				{Enemy: "fast", Count: 1, Start: 5},
+			},
+			Break: 10,
+		},
+		{
+			Groups: []Group{{Enemy: "basic", Count: 1, Zones: map[string]float32{"top": 1}}},
+		},
+	}})
+}
+
+// singles is pack size of enemies that come one by one
+func singles(string) int {
+	return 1
+}
+
+// spawnDue spawns everything that is due and returns names of spawned enemies
+func spawnDue(s *Script) []string {
+	var spawned []string
+	for g, ok := s.Next(); ok; g, ok = s.Next() {
+		spawned = append(spawned, g.Enemy)
+		s.Spawned()
+	}
+	return spawned
+}
+
+func TestScript(t *testing.T) {
+	t.Run("groups spawn on time", func(t *testing.T) {
+		s := newTestScript()
+
+		if got := spawnDue(s); len(got) != 1 || got[0] != "basic" {
+			t.Errorf("at start: got %v, want one basic", got)
+		}
+		s.Update(1, 1)
+		if got := spawnDue(s); len(got) != 1 {
+			t.Errorf("after interval: got %v, want one basic", got)
+		}
+		s.Update(1, 2)
+		if got := spawnDue(s); len(got) != 0 {
+			t.Errorf("before fast group starts: got %v, want nothing", got)
+		}
+		s.Update(3, 2)
+		if got := spawnDue(s); len(got) != 1 || got[0] != "fast" {
+			t.Errorf("after fast group starts: got %v, want one fast", got)
+		}
+		if got := s.Remaining(2, singles); got != 2 {
+			t.Errorf("got %d remaining, want 2 alive", got)
+		}
+	})
+
+	t.Run("not spawned enemy is due again", func(t *testing.T) {
+		s := newTestScript()
+
+		s.Next()
+		if g, ok := s.Next(); !ok || g.Enemy != "basic" {
+			t.Errorf("got %v, want basic to be due until spawned", g)
+		}
+		if got := s.Remaining(0, singles); got != 3 {
+			t.Errorf("got %d remaining, want 3", got)
+		}
+		packs := func(enemy string) int {
+			if enemy == "basic" {
+				return 3
+			}
+			return 1
+		}
+		if got := s.Remaining(0, packs); got != 7 {
+			t.Errorf("got %d remaining, want 2 packs of 3 and one single", got)
+		}
+	})
+
+	t.Run("break between waves", func(t *testing.T) {
+		s := newTestScript()
+		s.Update(5, 0)
+		spawnDue(s)
+
+		s.Update(1, 3)
+		if s.State() != Fighting {
+			t.Fatalf("wave ended while enemies are alive")
+		}
+		s.Update(1, 0)
+		if s.State() != Break || s.BreakLeft() != 10 {
+			t.Fatalf("got state %v with %vs left, want 10s break", s.State(), s.BreakLeft())
+		}
+		if _, ok := s.Next(); ok {
+			t.Errorf("enemy is due during break")
+		}
+		s.Update(10, 0)
+		if s.State() != Fighting || s.Wave() != 2 {
+			t.Fatalf("got state %v of wave %d, want second wave", s.State(), s.Wave())
+		}
+	})
+
+	t.Run("break can be skipped", func(t *testing.T) {
+		s := newTestScript()
+		s.Update(5, 0)
+		spawnDue(s)
+		s.Update(0, 0)
+
+		s.SkipBreak()
+		if s.State() != Fighting || s.Wave() != 2 {
+			t.Fatalf("got state %v of wave %d, want second wave", s.State(), s.Wave())
+		}
+	})
+
+	t.Run("clearing the last wave finishes script", func(t *testing.T) {
+		s := newTestScript()
+		s.Update(5, 0)
+		spawnDue(s)
+		s.Update(0, 0)
+		s.SkipBreak()
+		spawnDue(s)
+
+		s.Update(1, 0)
+		if s.State() != Done {
+			t.Errorf("got state %v, want done", s.State())
+		}
+	})
+}
@@ -1,24 +1,49 @@
 package main
 
 import (
+	"cmp"
 	"embed"
+	"flag"
 	"fmt"
+	"io/fs"
 	"math"
 	"math/rand"
 	"os"
+	"runtime"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
 	"github.com/pechorka/illuminate-game-jam/internal/consumables/flare"
 	"github.com/pechorka/illuminate-game-jam/internal/consumables/grenade"
+	"github.com/pechorka/illuminate-game-jam/internal/contract"
 	"github.com/pechorka/illuminate-game-jam/internal/db"
+	"github.com/pechorka/illuminate-game-jam/internal/director"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
+	"github.com/pechorka/illuminate-game-jam/internal/effects"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
 	"github.com/pechorka/illuminate-game-jam/internal/enemies/basic"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/boss"
 	"github.com/pechorka/illuminate-game-jam/internal/enemies/fast"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/lighteater"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/spitter"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/splitter"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/swarm"
 	"github.com/pechorka/illuminate-game-jam/internal/enemies/tank"
+	"github.com/pechorka/illuminate-game-jam/internal/events"
+	"github.com/pechorka/illuminate-game-jam/internal/objective"
+	"github.com/pechorka/illuminate-game-jam/internal/obstacle"
 	"github.com/pechorka/illuminate-game-jam/internal/projectile"
 	"github.com/pechorka/illuminate-game-jam/internal/soldier"
+	"github.com/pechorka/illuminate-game-jam/internal/spawnzone"
+	"github.com/pechorka/illuminate-game-jam/internal/stats"
+	"github.com/pechorka/illuminate-game-jam/internal/steering"
+	"github.com/pechorka/illuminate-game-jam/internal/vip"
+	"github.com/pechorka/illuminate-game-jam/internal/waves"
 	"github.com/pechorka/illuminate-game-jam/pkg/data_structures/quadtree"
+	"github.com/pechorka/illuminate-game-jam/pkg/navgrid"
 	"github.com/pechorka/illuminate-game-jam/pkg/rlutils"
 	"go.etcd.io/bbolt"
 
@@ -28,6 +53,9 @@ import (
 //go:embed assets
 var assets embed.FS
 
+// assetsFS is where assets are loaded from, in dev mode it's the disk
+var assetsFS fs.FS = assets
+
 const (
 	quadtreeCapacity = 10
 )
@@ -50,13 +78,6 @@ var (
 	centerLabelColor    = rl.White
 )
 
-var (
-	initialFlareCount   = 50
-	initialGrenadeCount = 5
-	initialSpawnRate    = float32(1)
-	spawnRateLimit      = float32(0.1)
-)
-
 // TODO: disgusting global variables
 var closeWindow = false
 
@@ -65,6 +86,28 @@ var (
 )
 
 func main() {
+	balancePath := flag.String("balance", "balance.json", "file that overrides default game balance, if exists")
+	wavesPath := flag.String("waves", "waves.json", "file that replaces default waves of wave mode, if exists")
+	dev := flag.Bool("dev", false, "load assets from disk and reload them when they change")
+	flag.Parse()
+
+	if *dev {
+		assetsFS = os.DirFS(".")
+	}
+
+	gameBalance, err := loadBalance(*balancePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid game balance:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	waveFile, err := loadWaves(*wavesPath, gameBalance)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid waves:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	boltCli, err := bbolt.Open("light-in-night.db", os.ModePerm, nil)
 	if err != nil {
 		panic(err)
@@ -72,6 +115,7 @@ func main() {
 	defer boltCli.Close()
 
 	db := db.New(boltCli)
+	bus := events.NewBus()
 
 	gb := &gameBoundaries{
 		screenWidth:  1280,
@@ -91,10 +135,19 @@ func main() {
 			// titleMusic: loadMusicStream("assets/sounds/music/title.mp3"),
 			soldier: loadTextureFromImage("assets/soldier.png"),
 			levelup: loadTextureFromImage("assets/levelup.png"),
+			vip:     loadTextureFromImage("assets/vip.png"),
 			enemy: &enemyAssets{
-				basic: loadTextureFromImage("assets/enemy/basic.png"),
-				fast:  loadTextureFromImage("assets/enemy/fast.png"),
-				tank:  loadTextureFromImage("assets/enemy/tank.png"),
+				basic:   loadTextureFromImage("assets/enemy/basic.png"),
+				fast:    loadTextureFromImage("assets/enemy/fast.png"),
+				tank:    loadTextureFromImage("assets/enemy/tank.png"),
+				spitter: loadTextureFromImage("assets/enemy/spitter.png"),
+				boss:    loadTextureFromImage("assets/enemy/boss.png"),
+
+				splitter:  loadTextureFromImage("assets/enemy/splitter.png"),
+				splitling: loadTextureFromImage("assets/enemy/splitling.png"),
+				swarm:     loadTextureFromImage("assets/enemy/swarm.png"),
+
+				lighteater: loadTextureFromImage("assets/enemy/lighteater.png"),
 			},
 			consumables: &consumableAssets{
 				flare:   loadTextureFromImage("assets/consumables/flare.png"),
@@ -105,15 +158,29 @@ func main() {
 		quadtree:     quadtree.NewQuadtree(gb.arenaBoundaries, quadtreeCapacity),
 
 		itemStorage: &itemStorage{
-			flareCount:   initialFlareCount,
-			grenadeCount: initialGrenadeCount,
+			flareCount:   gameBalance.InitialFlareCount,
+			grenadeCount: gameBalance.InitialGrenadeCount,
 		},
 		selectedConsumable: flares,
-		spawnRate:          initialSpawnRate,
+		spawnRate:          gameBalance.InitialSpawnRate,
+		director:           director.New(gameBalance.Director),
+		balance:            gameBalance,
+		loadedBalance:      gameBalance,
+		waveFile:           waveFile,
 
 		gameScreen: gameScreenMainMenu,
 
 		db: db,
+
+		events: bus,
+		stats:  stats.Subscribe(bus),
+
+		debug: &debugOverlay{},
+	}
+	gs.setDifficulty(difficultyNormal)
+	gs.setObstacles(nil)
+	if *dev {
+		gs.dev = newDevReloader(*balancePath, gs.assets)
 	}
 
 	// rl.PlayMusicStream(gs.assets.titleMusic)
@@ -135,21 +202,53 @@ func main() {
 	rl.CloseWindow()
 }
 
+func loadBalance(overridePath string) (*balance.Balance, error) {
+	defaults, err := fs.ReadFile(assetsFS, "assets/balance.json")
+	if err != nil {
+		return nil, err
+	}
+	return balance.Load(defaults, overridePath)
+}
+
+// loadWaves loads wave file, enemies of waves have to be in the balance
+func loadWaves(path string, b *balance.Balance) (*waves.File, error) {
+	defaults, err := fs.ReadFile(assetsFS, "assets/waves.json")
+	if err != nil {
+		return nil, err
+	}
+	return waves.Load(defaults, path, func(enemy string) bool {
+		_, ok := b.Enemies[enemy]
+		return ok || enemy == boss.Name
+	})
+}
+
 func loadTextureFromImage(imgPath string) rl.Texture2D {
-	file, err := assets.ReadFile(imgPath)
+	texture, err := loadTexture(imgPath)
 	if err != nil {
 		panic(err)
 	}
+	return texture
+}
+
+// loadTexture fails if image can't be read or decoded, for example when it's half written
+func loadTexture(imgPath string) (rl.Texture2D, error) {
+	file, err := fs.ReadFile(assetsFS, imgPath)
+	if err != nil {
+		return rl.Texture2D{}, err
+	}
 	fileExtention := imgPath[strings.LastIndexByte(imgPath, '.'):]
 	img := rl.LoadImageFromMemory(fileExtention, file, int32(len(file)))
 	defer rl.UnloadImage(img)
 	texture := rl.LoadTextureFromImage(img)
+	if texture.ID == 0 {
+		return rl.Texture2D{}, fmt.Errorf("can't load texture from %s", imgPath)
+	}
 
-	return texture
+	return texture, nil
 }
 
 func loadImageFromMemory(imgPath string) *rl.Image {
-	file, err := assets.ReadFile(imgPath)
+	file, err := fs.ReadFile(assetsFS, imgPath)
 	if err != nil {
 		panic(err)
 	}
@@ -158,7 +257,7 @@ func loadImageFromMemory(imgPath string) *rl.Image {
 }
 
 func loadMusicStream(musicPath string) rl.Music {
-	file, err := assets.ReadFile(musicPath)
+	file, err := fs.ReadFile(assetsFS, musicPath)
 	if err != nil {
 		panic(err)
 	}
@@ -171,6 +270,7 @@ func loadMusicStream(musicPath string) rl.Music {
 type gameAssets struct {
 	soldier    rl.Texture2D
 	levelup    rl.Texture2D
+	vip        rl.Texture2D
 	titleMusic rl.Music
 
 	enemy       *enemyAssets
@@ -179,18 +279,53 @@ type gameAssets struct {
 
 func (ga *gameAssets) unload() {
 	rl.UnloadTexture(ga.soldier)
+	rl.UnloadTexture(ga.vip)
 	rl.UnloadMusicStream(ga.titleMusic)
 	rl.UnloadTexture(ga.enemy.basic)
 	rl.UnloadTexture(ga.enemy.fast)
 	rl.UnloadTexture(ga.enemy.tank)
+	rl.UnloadTexture(ga.enemy.spitter)
+	rl.UnloadTexture(ga.enemy.boss)
+	rl.UnloadTexture(ga.enemy.splitter)
+	rl.UnloadTexture(ga.enemy.splitling)
+	rl.UnloadTexture(ga.enemy.swarm)
+	rl.UnloadTexture(ga.enemy.lighteater)
 	rl.UnloadTexture(ga.consumables.flare)
 	rl.UnloadTexture(ga.consumables.grenade)
 }
 
+// textures returns textures by their path in assets
+func (ga *gameAssets) textures() map[string]*rl.Texture2D {
+	return map[string]*rl.Texture2D{
+		"assets/soldier.png":             &ga.soldier,
+		"assets/levelup.png":             &ga.levelup,
+		"assets/vip.png":                 &ga.vip,
+		"assets/enemy/basic.png":         &ga.enemy.basic,
+		"assets/enemy/fast.png":          &ga.enemy.fast,
+		"assets/enemy/tank.png":          &ga.enemy.tank,
+		"assets/enemy/spitter.png":       &ga.enemy.spitter,
+		"assets/enemy/boss.png":          &ga.enemy.boss,
+		"assets/enemy/splitter.png":      &ga.enemy.splitter,
+		"assets/enemy/splitling.png":     &ga.enemy.splitling,
+		"assets/enemy/swarm.png":         &ga.enemy.swarm,
+		"assets/enemy/lighteater.png":    &ga.enemy.lighteater,
+		"assets/consumables/flare.png":   &ga.consumables.flare,
+		"assets/consumables/grenade.png": &ga.consumables.grenade,
+	}
+}
+
 type enemyAssets struct {
-	basic rl.Texture2D
-	fast  rl.Texture2D
-	tank  rl.Texture2D
+	basic   rl.Texture2D
+	fast    rl.Texture2D
+	tank    rl.Texture2D
+	spitter rl.Texture2D
+	boss    rl.Texture2D
+
+	splitter  rl.Texture2D
+	splitling rl.Texture2D
+	swarm     rl.Texture2D
+
+	lighteater rl.Texture2D
 }
 
 type consumableAssets struct {
@@ -209,6 +344,58 @@ const (
 	gameScreenHowToPlay
 )
 
+type gameMode int
+
+const (
+	gameModeEndless gameMode = iota
+	gameModeWaves
+)
+
+func (m gameMode) String() string {
+	if m == gameModeWaves {
+		return "Waves"
+	}
+	return "Endless"
+}
+
+type difficulty int
+
+// normal is the zero value, so it's the default
+const (
+	difficultyNormal difficulty = iota
+	difficultyEasy
+	difficultyHard
+	difficultyNightmare
+)
+
+// difficulties in the order they are shown on setup screen
+var difficulties = []difficulty{difficultyEasy, difficultyNormal, difficultyHard, difficultyNightmare}
+
+func (d difficulty) String() string {
+	switch d {
+	case difficultyEasy:
+		return "Easy"
+	case difficultyHard:
+		return "Hard"
+	case difficultyNightmare:
+		return "Nightmare"
+	}
+	return "Normal"
+}
+
+// stats returns preset of the difficulty from balance
+func (d difficulty) stats(b balance.Difficulties) balance.Difficulty {
+	switch d {
+	case difficultyEasy:
+		return b.Easy
+	case difficultyHard:
+		return b.Hard
+	case difficultyNightmare:
+		return b.Nightmare
+	}
+	return b.Normal
+}
+
 type gameBoundaries struct {
 	screenWidth  int
 	screenHeight int
@@ -266,20 +453,6 @@ func (gb *gameBoundaries) updateBoundaries() {
 	}
 }
 
-type enemy interface {
-	GetID() int
-	IsDead() bool
-	Reward() int
-	MoveTowards(rl.Vector2) rl.Vector2
-	MoveAway(rl.Vector2) rl.Vector2
-	GetPos() rl.Vector2
-	UpdatePosition(rl.Vector2)
-	Draw()
-	DealDamage() float32
-	TakeDamage(float32)
-	Boundaries() rl.Rectangle
-}
-
 type gameState struct {
 	boundaries   *gameBoundaries
 	assets       *gameAssets
@@ -288,27 +461,56 @@ type gameState struct {
 	flares       []*flare.Flare
 	grenades     []*grenade.Grenade
 	soldiers     []*soldier.Soldier
-	enemies      []enemy
+	vip          *vip.VIP // nil without escort contract
+	enemies      []enemies.Enemy
 	projectiles  []*projectile.Projectile
+	obstacles    []*obstacle.Obstacle
+	navGrid      *navgrid.Grid
+	zones        []spawnzone.Zone // where enemies come from, rebuilt with obstacles
+	pending      []*pendingSpawn  // telegraphed packs that are about to spawn
+	soldierField *navgrid.Field   // distance to soldiers, rebuilt every tick before enemies move
 
 	itemStorage        *itemStorage
 	selectedConsumable consumable
 
 	gameScreen      gameScreen
+	gameMode        gameMode
+	difficulty      difficulty
+	objectiveKind   objective.Kind
 	paused          bool
+	howToPlayPage   int
 	enemeSpawnedAgo float32
 	spawnRate       float32
+	director        *director.Director
 
 	score int
 	money int
 
 	gameTime float32
 
-	db *db.DB
+	nextBossAt   float32 // game time
+	bossesKilled int
+
+	objective *objective.Objective // nil in wave mode, waves decide when it's won
+	contracts contract.Active
+
+	waveFile *waves.File
+	waves    *waves.Script // nil in endless mode
+
+	db            *db.DB
+	balance       *balance.Balance // scaled by difficulty
+	loadedBalance *balance.Balance // as loaded from files
+	enemyRegistry *enemies.Registry[enemies.Enemy]
+
+	events *events.Bus
+	stats  *stats.Stats
 
 	nameInput string
 	victory   bool
 
+	debug *debugOverlay
+	dev   *devReloader // nil if not in dev mode
+
 	// draggingSoldier *soldier.Soldier
 }
 
@@ -328,12 +530,23 @@ func (gs *gameState) renderFrame() {
 	if rl.IsKeyPressed(rl.KeySpace) {
 		gs.paused = !gs.paused
 	}
+	if rl.IsKeyPressed(rl.KeyF3) {
+		gs.debug.enabled = !gs.debug.enabled
+	}
+
+	if gs.dev != nil {
+		gs.hotReload()
+	}
 
 	if gs.boundaries.update() {
 		gs.prevQuadtree = quadtree.NewQuadtree(gs.boundaries.arenaBoundaries, quadtreeCapacity)
 		gs.quadtree = quadtree.NewQuadtree(gs.boundaries.arenaBoundaries, quadtreeCapacity)
 	}
 
+	gs.debug.queries = gs.prevQuadtree.Queries() + gs.quadtree.Queries()
+	gs.prevQuadtree.ResetQueries()
+	gs.quadtree.ResetQueries()
+
 	gs.prevQuadtree, gs.quadtree = gs.quadtree, gs.prevQuadtree
 	gs.quadtree.Clear()
 	rl.ClearBackground(rl.Black)
@@ -412,6 +625,116 @@ func (gs *gameState) renderSetupGameScreen() {
 	spacing := int32(50)
 	fontSize := int32(30)
 
+	gameModeItem := "Select mode: "
+	gameModeItemWidth := rl.MeasureText(gameModeItem, fontSize)
+	gameModeItemX := x - gameModeItemWidth/2
+	gameModeItemY := y - 2*spacing
+
+	rl.DrawText(gameModeItem, gameModeItemX, gameModeItemY, fontSize, rl.White)
+
+	difficultyItem := "Select difficulty: "
+	difficultyItemWidth := rl.MeasureText(difficultyItem, fontSize)
+	difficultyItemX := x - difficultyItemWidth/2
+	difficultyItemY := gameModeItemY - spacing
+
+	rl.DrawText(difficultyItem, difficultyItemX, difficultyItemY, fontSize, rl.White)
+
+	difficultyX := difficultyItemX + difficultyItemWidth
+	for _, d := range difficulties {
+		option := d.String()
+		difficultyWidth := rl.MeasureText(option, fontSize)
+
+		difficultyX += 10
+		difficultyBoundaries := rl.Rectangle{
+			X:      float32(difficultyX) - 5,
+			Y:      float32(difficultyItemY) - 5,
+			Width:  float32(difficultyWidth) + 10,
+			Height: float32(centerLabelFontSize),
+		}
+
+		color := rl.White
+		if rl.CheckCollisionPointRec(rl.GetMousePosition(), difficultyBoundaries) {
+			color = rl.Green
+			if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+				gs.setDifficulty(d)
+			}
+		}
+		if d == gs.difficulty {
+			color = rl.Green
+		}
+
+		rl.DrawRectangleLinesEx(difficultyBoundaries, 2, color)
+		rl.DrawText(option, difficultyX, difficultyItemY, fontSize, color)
+		difficultyX += difficultyWidth + 10
+	}
+
+	modeX := gameModeItemX + gameModeItemWidth
+	for _, mode := range []gameMode{gameModeEndless, gameModeWaves} {
+		option := mode.String()
+		modeWidth := rl.MeasureText(option, fontSize)
+
+		modeX += 10
+		modeBoundaries := rl.Rectangle{
+			X:      float32(modeX) - 5,
+			Y:      float32(gameModeItemY) - 5,
+			Width:  float32(modeWidth) + 10,
+			Height: float32(centerLabelFontSize),
+		}
+
+		color := rl.White
+		if rl.CheckCollisionPointRec(rl.GetMousePosition(), modeBoundaries) {
+			color = rl.Green
+			if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+				gs.gameMode = mode
+			}
+		}
+		if mode == gs.gameMode {
+			color = rl.Green
+		}
+
+		rl.DrawRectangleLinesEx(modeBoundaries, 2, color)
+		rl.DrawText(option, modeX, gameModeItemY, fontSize, color)
+		modeX += modeWidth + 10
+	}
+
+	if gs.gameMode == gameModeEndless {
+		objectiveItem := "Select objective: "
+		objectiveItemWidth := rl.MeasureText(objectiveItem, fontSize)
+		objectiveItemX := x - objectiveItemWidth/2
+		objectiveItemY := y - spacing
+
+		rl.DrawText(objectiveItem, objectiveItemX, objectiveItemY, fontSize, rl.White)
+
+		objectiveX := objectiveItemX + objectiveItemWidth
+		for _, kind := range objective.Kinds {
+			option := kind.String()
+			objectiveWidth := rl.MeasureText(option, fontSize)
+
+			objectiveX += 10
+			objectiveBoundaries := rl.Rectangle{
+				X:      float32(objectiveX) - 5,
+				Y:      float32(objectiveItemY) - 5,
+				Width:  float32(objectiveWidth) + 10,
+				Height: float32(centerLabelFontSize),
+			}
+
+			color := rl.White
+			if rl.CheckCollisionPointRec(rl.GetMousePosition(), objectiveBoundaries) {
+				color = rl.Green
+				if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+					gs.objectiveKind = kind
+				}
+			}
+			if kind == gs.objectiveKind {
+				color = rl.Green
+			}
+
+			rl.DrawRectangleLinesEx(objectiveBoundaries, 2, color)
+			rl.DrawText(option, objectiveX, objectiveItemY, fontSize, color)
+			objectiveX += objectiveWidth + 10
+		}
+	}
+
 	numberOfSoldiersItem := "Select number of soldiers: "
 	numberOfSoldiersItemWidth := rl.MeasureText(numberOfSoldiersItem, fontSize)
 	numberOfSoldiersItemX := x - numberOfSoldiersItemWidth/2
@@ -438,6 +761,7 @@ func (gs *gameState) renderSetupGameScreen() {
 			color = rl.Green
 			if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
 				soldierCount = i + 1
+				gs.generateObstacles()
 				gs.placeSoldiersOnRandomPositions(soldierCount)
 			}
 		}
@@ -487,13 +811,57 @@ func (gs *gameState) renderSetupGameScreen() {
 		if rl.CheckCollisionPointRec(rl.GetMousePosition(), startGameItemBoundaries) {
 			color = rl.Green
 			if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
-				gs.gameScreen = gameScreenGame
+				gs.startRun()
 			}
 		}
 	}
 	rl.DrawText(startGameItem, startGameItemX, startGameItemY, fontSize, color)
 }
 
+// setDifficulty scales loaded balance by difficulty preset, it's chosen before the run starts
+func (gs *gameState) setDifficulty(d difficulty) {
+	gs.difficulty = d
+	gs.applyBalance(gs.loadedBalance.WithDifficulty(d.stats(gs.loadedBalance.Difficulties)))
+	gs.itemStorage.flareCount = gs.balance.InitialFlareCount
+	gs.itemStorage.grenadeCount = gs.balance.InitialGrenadeCount
+}
+
+func (gs *gameState) startRun() {
+	if gs.gameMode == gameModeWaves {
+		gs.waves = waves.NewScript(gs.waveFile)
+	} else {
+		gs.objective = objective.New(gs.objectiveKind, gs.balance.Objectives)
+	}
+	gs.gameScreen = gameScreenGame
+	events.Publish(gs.events, events.RunStarted{Soldiers: len(gs.soldiers)})
+}
+
+// generateObstacles places new random obstacles and nests in the arena
+func (gs *gameState) generateObstacles() {
+	arena := gs.boundaries.arenaBoundaries
+	gs.setObstacles(obstacle.Generate(arena, gs.balance.Arena))
+	gs.setNests(obstacle.Nests(arena, gs.balance.Arena, gs.obstacles))
+}
+
+// setObstacles replaces obstacles and builds navigation grid around them.
+// Old nests may be covered by new obstacles, so they are removed.
+func (gs *gameState) setObstacles(obstacles []*obstacle.Obstacle) {
+	gs.obstacles = obstacles
+	gs.navGrid = navgrid.New(gs.boundaries.arenaBoundaries, gs.balance.Arena.CellSize)
+	for _, o := range gs.obstacles {
+		gs.navGrid.Block(o.Boundaries())
+	}
+	gs.setNests(nil)
+}
+
+// spawnZoneDepth is how far from the arena border edge and corner zones reach
+const spawnZoneDepth = 100
+
+// setNests rebuilds spawn zones with given nests
+func (gs *gameState) setNests(nests []rl.Rectangle) {
+	gs.zones = spawnzone.Layout(gs.boundaries.arenaBoundaries, spawnZoneDepth, nests)
+}
+
 func (gs *gameState) placeSoldiersOnRandomPositions(soldierCount int) {
 	gs.soldiers = make([]*soldier.Soldier, 0, soldierCount)
 	ab := gs.boundaries.arenaBoundaries
@@ -509,10 +877,10 @@ func (gs *gameState) placeSoldiersOnRandomPositions(soldierCount int) {
 			X: rlutils.RandomFloat(ab.X+100, ab.Width+ab.X-100),
 			Y: rlutils.RandomFloat(ab.Y+100, ab.Height+ab.Y-100),
 		}
-		newSoldier := soldier.FromPos(pos, gs.assets.soldier, gs.assets.levelup)
+		newSoldier := soldier.FromPos(pos, gs.assets.soldier, gs.assets.levelup, gs.balance.Soldier, gs.events)
 
 		collisions := quadtree.Query(newSoldier.Boundaries())
-		if len(collisions) > 0 {
+		if len(collisions) > 0 || gs.blockedByObstacles(newSoldier.Boundaries()) {
 			continue
 		}
 		quadtree.Insert(newSoldier.ID, newSoldier.Boundaries(), newSoldier)
@@ -534,25 +902,35 @@ func (gs *gameState) renderTodoScreen() {
 
 func (gs *gameState) renderGame() {
 	if len(gs.soldiers) == 0 {
-		gs.gameScreen = gameScreenOver
+		gs.endRun(false)
 		return
 	}
 
+	ecs.Collide(gs.quadtree, gs.obstacles)
+
 	if !gs.paused {
 		gs.gameTime += rl.GetFrameTime()
 
+		if gs.waves != nil && rl.IsKeyPressed(rl.KeyN) {
+			gs.waves.SkipBreak()
+		}
 		gs.useConsumable()
 		gs.processFlares()
 		gs.processGrenades()
 
 		gs.processProjectiles()
 
+		gs.progressTelegraphs(rl.GetFrameTime())
 		gs.spawnEnemies()
 		flaredEnemies := gs.processEnemies()
 		gs.cleanupDeadEnemies()
+		gs.removeRetreatedEnemies()
+		gs.checkObjective()
 
 		gs.cleanupDeadSoldiers()
 		gs.processSoldiers(flaredEnemies)
+		gs.processVIP()
+		gs.processContracts()
 	}
 
 	gs.renderHeader()
@@ -563,11 +941,20 @@ func (gs *gameState) renderGame() {
 	// gs.renderItemSelector()
 	// gs.placeDraggedSoldier()
 	// gs.renderDraggingSoldier()
-	gs.renderFlares()
-	gs.renderGrenades()
-	gs.renderProjectiles()
-	gs.renderEnemies()
-	gs.renderSoldiers()
+	gs.renderSky()
+	gs.renderNests()
+	ecs.Render(gs.flares)
+	ecs.Render(gs.obstacles)
+	ecs.Render(gs.grenades)
+	ecs.Render(gs.projectiles)
+	ecs.Render(gs.enemies)
+	gs.renderTelegraphs()
+	ecs.Render(gs.soldiers)
+	if gs.vip != nil {
+		gs.vip.Draw()
+	}
+	gs.renderWaveHUD()
+	gs.renderDebugOverlay()
 }
 
 func (gs *gameState) renderHeader() {
@@ -590,12 +977,96 @@ func (gs *gameState) renderHeader() {
 	rl.DrawText(scoreText, widthOffset, 10, 20, rl.White)
 	widthOffset += scoreTextWidth + 10
 	rl.DrawText(moneyText, widthOffset, 10, 20, rl.White)
+	widthOffset += rl.MeasureText(moneyText, 20) + 10
+
+	for _, c := range gs.contracts.List() {
+		status := c.Status()
+		rl.DrawText(status, widthOffset, 10, 20, rl.Gold)
+		widthOffset += rl.MeasureText(status, 20) + 10
+	}
+
+	gs.renderBossHealthBar(float32(widthOffset))
+
+	if gs.objective != nil {
+		status := gs.objective.Status(gs.objectiveProgress())
+		rl.DrawText(status, int32(headerBoundaries.Width-110)-rl.MeasureText(status, 20), 10, 20, rl.White)
+	}
 
 	if gs.paused {
 		rl.DrawText("Paused", int32(headerBoundaries.Width-100), 10, 20, rl.White)
 	}
 }
 
+// renderWaveHUD shows wave progress at the top of arena, during breaks it counts down to next wave
+func (gs *gameState) renderWaveHUD() {
+	if gs.waves == nil {
+		return
+	}
+	var text string
+	switch gs.waves.State() {
+	case waves.Fighting:
+		text = fmt.Sprintf("Wave %d/%d - enemies left: %d",
+			gs.waves.Wave(), gs.waves.Waves(), gs.waves.Remaining(gs.enemiesLeft(), gs.packSize))
+	case waves.Break:
+		text = fmt.Sprintf("Wave %d/%d cleared - next wave in %ds, press N to start it now",
+			gs.waves.Wave(), gs.waves.Waves(), int(math.Ceil(float64(gs.waves.BreakLeft()))))
+	default:
+		return
+	}
+
+	fontSize := int32(20)
+	arena := gs.boundaries.arenaBoundaries
+	width := rl.MeasureText(text, fontSize)
+	x := int32(arena.X+arena.Width/2) - width/2
+	y := int32(arena.Y) + 10
+	rl.DrawRectangle(x-5, y-2, width+10, fontSize+4, rl.Fade(rl.Black, 0.6))
+	rl.DrawText(text, x, y, fontSize, rl.White)
+}
+
+// renderBossHealthBar draws health of the first alive boss in the middle of header,
+// marks show where next phases start. Bar shrinks to make room for what is drawn left of it.
+func (gs *gameState) renderBossHealthBar(left float32) {
+	var b *boss.Boss
+	for _, e := range gs.enemies {
+		if eb, ok := e.(*boss.Boss); ok && !eb.IsDead() {
+			b = eb
+			break
+		}
+	}
+	if b == nil {
+		return
+	}
+
+	headerBoundaries := gs.boundaries.headerBoundaries
+	label := "Boss"
+	if gs.objective != nil && gs.objective.Kind() == objective.Bosses {
+		label += " " + strconv.Itoa(gs.bossesKilled+1) + "/" + strconv.Itoa(gs.balance.Objectives.Bosses)
+	}
+	labelWidth := rl.MeasureText(label, 20)
+	barX := max(headerBoundaries.Width*0.4, left+float32(labelWidth)+10)
+	bar := rl.Rectangle{
+		X:      barX,
+		Y:      headerBoundaries.Y + 8,
+		Width:  headerBoundaries.Width*0.8 - barX,
+		Height: headerBoundaries.Height - 16,
+	}
+	rl.DrawText(label, int32(bar.X)-labelWidth-10, 10, 20, rl.White)
+
+	rl.DrawRectangleRec(bar, rl.DarkGray)
+	health := bar
+	health.Width *= max(b.HP/b.MaxHP, 0)
+	color := rl.Maroon
+	if b.Charging() {
+		color = rl.Red
+	}
+	rl.DrawRectangleRec(health, color)
+	for _, p := range b.Phases()[b.Phase():] {
+		x := int32(bar.X + bar.Width*p.HealthBelow)
+		rl.DrawLine(x, int32(bar.Y), x, int32(bar.Y+bar.Height), rl.White)
+	}
+	rl.DrawRectangleLinesEx(bar, 2, rl.Black)
+}
+
 func gameTimeToString(time float32) string {
 	timeInt := int(time)
 	minutes := timeInt / 60
@@ -611,69 +1082,84 @@ type shopItem struct {
 	icon        rl.Texture2D
 	ctype       consumable
 	quickBuyBtn int32
+	buy         func() bool // for items that aren't consumables, false if item can't be bought right now
 }
 
 func (gs *gameState) renderFooter() {
 	footerBoundaries := gs.boundaries.footerBoundaries
 	rl.DrawRectangleRec(footerBoundaries, rl.Gray)
-	// TODO: shop can have items to increase difficulty, but with higher rewards
-	// for example: princess that needs to be protected for some time, but gives a lot of money
 	mockItems := []shopItem{
 		{
-			price: 10, count: 10,
+			price: gs.balance.Shop["flare"].Price, count: gs.balance.Shop["flare"].Count,
 			name: "Flare", description: "Reveals enemies",
 			icon:        gs.assets.consumables.flare,
 			ctype:       flares,
 			quickBuyBtn: rl.KeyQ,
 		},
 		{
-			price: 20, count: 1,
-			name: "Grenade", description: "Deals damage",
+			price: gs.balance.Shop["grenade"].Price, count: gs.balance.Shop["grenade"].Count,
+			name: "Grenade", description: "Burns and stuns",
 			icon:        gs.assets.consumables.grenade,
 			ctype:       grenades,
 			quickBuyBtn: rl.KeyW,
 		},
+		{
+			price: gs.balance.Escort.Price, count: 1,
+			name: "Escort", description: "VIP that enemies go for first, pays a lot if it survives",
+			icon:        gs.assets.vip,
+			quickBuyBtn: rl.KeyE,
+			buy:         gs.startEscort,
+		},
 	}
 	itemWidth := float32(100)
 	itemHeight := footerBoundaries.Height - 20 // 10px margin on each side
 	buyItem := func(item shopItem) {
-		if gs.money >= item.price {
-			gs.money -= item.price
-			switch item.ctype {
-			case flares:
-				gs.itemStorage.flareCount += item.count
-			case grenades:
-				gs.itemStorage.grenadeCount += item.count
-			}
+		if gs.money < item.price {
+			return
 		}
-	}
-	for i, item := range mockItems {
-		itemBoundaries := rl.Rectangle{
-			X:      footerBoundaries.X + 10 + float32(i)*(itemWidth+10),
-			Y:      footerBoundaries.Y + 10,
-			Width:  itemWidth,
-			Height: itemHeight,
+		if item.buy != nil && !item.buy() {
+			return
 		}
-		rl.DrawRectangleLinesEx(itemBoundaries, 2, rl.White)
-
-		iconPos := rl.Vector2{
-			X: itemBoundaries.X + itemBoundaries.Width/2 - float32(item.icon.Width/2),
-			Y: itemBoundaries.Y + itemBoundaries.Height/2 - float32(item.icon.Height/2),
+		gs.money -= item.price
+		switch item.ctype {
+		case flares:
+			gs.itemStorage.flareCount += item.count
+		case grenades:
+			gs.itemStorage.grenadeCount += item.count
 		}
-		rl.DrawTextureV(item.icon, iconPos, rl.White)
-
-		// display description when hovered
-		if rl.CheckCollisionPointRec(rl.GetMousePosition(), itemBoundaries) {
-			// description should be displayed above the item with border
-			gs.drawShopItemDescription(item)
-
-			if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
-				buyItem(item)
-			}
+		events.Publish(gs.events, events.ItemBought{
+			Name:  item.name,
+			Price: item.price,
+			Count: item.count,
+		})
+	}
+	// contracts go in their own section after the rest of the shop
+	sections := []struct {
+		items []shopItem
+		color rl.Color
+	}{
+		{items: mockItems, color: rl.White},
+		{items: gs.contractItems(), color: rl.Gold},
+	}
+	itemX := footerBoundaries.X + 10
+	for i, section := range sections {
+		if i > 0 {
+			rl.DrawLineEx(
+				rl.Vector2{X: itemX, Y: footerBoundaries.Y + 10},
+				rl.Vector2{X: itemX, Y: footerBoundaries.Y + 10 + itemHeight},
+				2, rl.DarkGray,
+			)
+			itemX += 10
 		}
-
-		if rl.IsKeyPressed(item.quickBuyBtn) {
-			buyItem(item)
+		for _, item := range section.items {
+			itemBoundaries := rl.Rectangle{
+				X:      itemX,
+				Y:      footerBoundaries.Y + 10,
+				Width:  itemWidth,
+				Height: itemHeight,
+			}
+			itemX += itemWidth + 10
+			gs.renderShopItem(item, itemBoundaries, section.color, buyItem)
 		}
 	}
 
@@ -693,46 +1179,137 @@ func (gs *gameState) renderFooter() {
 	if rl.CheckCollisionPointRec(rl.GetMousePosition(), endRunTextBoundaries) {
 		color = rl.Green
 		if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
-			gs.gameScreen = gameScreenOver
+			gs.endRun(false)
 		}
 	}
 	rl.DrawText(endRunText, endRunTextX, endRunTextY, endRunFontSize, color)
 }
 
-func (gs *gameState) renderHowToPlayScreen() {
-	tutorialText := []string{
-		"Start a new game from the main menu and select the number of soldiers.",
-		"Use the left mouse button to deploy flares and grenades.",
-		"Flares reveal and repel enemies. Soldiers will shoot at enemies in flare range.",
-		"Switch between flares and grenades with the 1 and 2 keys.",
-		"Use money earned from defeating enemies to buy more flares and grenades.",
-		"You can quick buy flares and grenades with the Q and W keys.",
-		"Soldiers will automatically attack enemies in their range.",
+// renderShopItem draws item with its icon, or its name if item has no icon.
+// Item is bought on click or with its quick buy button.
+func (gs *gameState) renderShopItem(item shopItem, boundaries rl.Rectangle, color rl.Color, buyItem func(shopItem)) {
+	rl.DrawRectangleLinesEx(boundaries, 2, color)
+
+	if item.icon.Width > 0 {
+		iconPos := rl.Vector2{
+			X: boundaries.X + boundaries.Width/2 - float32(item.icon.Width/2),
+			Y: boundaries.Y + boundaries.Height/2 - float32(item.icon.Height/2),
+		}
+		rl.DrawTextureV(item.icon, iconPos, rl.White)
+	} else {
+		nameWidth := rl.MeasureText(item.name, 16)
+		rl.DrawText(item.name, int32(boundaries.X+boundaries.Width/2)-nameWidth/2, int32(boundaries.Y+boundaries.Height/2)-8, 16, color)
+	}
+
+	// display description when hovered
+	if rl.CheckCollisionPointRec(rl.GetMousePosition(), boundaries) {
+		// description should be displayed above the item with border
+		gs.drawShopItemDescription(item)
+
+		if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+			buyItem(item)
+		}
+	}
+
+	if rl.IsKeyPressed(item.quickBuyBtn) {
+		buyItem(item)
+	}
+}
+
+// contractQuickBuyBtns are quick buy buttons of contracts in the order of contract.Kinds
+var contractQuickBuyBtns = []int32{rl.KeyR, rl.KeyT, rl.KeyY}
+
+// contractItems are free shop items that sign contracts.
+// Frenzy isn't offered in wave mode, since waves decide when enemies spawn.
+func (gs *gameState) contractItems() []shopItem {
+	items := make([]shopItem, 0, len(contract.Kinds))
+	for i, kind := range contract.Kinds {
+		if kind == contract.Frenzy && gs.waves != nil {
+			continue
+		}
+		items = append(items, shopItem{
+			count:       1,
+			name:        kind.String(),
+			description: kind.Description(gs.balance.Contracts),
+			quickBuyBtn: contractQuickBuyBtns[i],
+			buy: func() bool {
+				return gs.signContract(kind)
+			},
+		})
+	}
+	return items
+}
+
+// howToPlayPages are pages of tutorial, each has to fit the default 1280x720 window
+var howToPlayPages = [][]string{
+	{
+		"Start a new game from the main menu and pick the number of soldiers.",
+		"Soldiers automatically attack enemies in their range.",
 		"The game ends when all soldiers are defeated.",
+		"The fewer soldiers you choose, the more money and score you earn.",
+		"Harder difficulties bring tougher enemies and higher prices,",
+		"but pay more for every kill.",
 		"Pause the game anytime with the spacebar.",
-		"The less soldiers you choose, the more money/score you earn.",
+		"Press F3 to toggle the debug overlay.",
 		"Don't delete light-in-night.db file, it contains your highscore.",
+	},
+	{
+		"Use the left mouse button to deploy flares and grenades.",
+		"Switch between flares and grenades with the 1 and 2 keys.",
+		"Flares reveal and repel enemies. Soldiers shoot at enemies in light.",
+		"Flare light blinds soldiers in it, so they can't shoot as far.",
+		"Grenades burn and stun everyone caught in the blast, soldiers too.",
+		"Walls, rocks and ruins stop enemies and bullets alike.",
+		"Spend money earned from kills in the shop, quick buy with Q and W.",
+		"Escort (E): enemies go for the VIP first. Keep it alive until",
+		"its timer runs out to get paid, losing it costs money.",
+		"Contracts (R, T and Y) make the night harder for a while,",
+		"but pay cash and score once they are over.",
+	},
+	{
+		"Enemies come from the edges, corners and nests.",
+		"A red marker flashes where they are about to appear.",
+		"Throw a flare on the marker to hold enemies back while it burns.",
+		"Beware of light eaters: they are drawn to flares and put them out.",
+		"Later on elites appear: outlined enemies that can be armoured,",
+		"vampiric, fast, explosive or flare shielded.",
+		"A boss arrives every few minutes.",
+		"In endless mode pick an objective: survive until dawn,",
+		"kill enough enemies or defeat bosses.",
+		"At dawn the sky brightens and enemies retreat. Hold on until they leave.",
+		"In wave mode enemies come in waves. Shop between waves",
+		"and clear the last one to win.",
+	},
+}
+
+func (gs *gameState) renderHowToPlayScreen() {
+	if rl.IsKeyPressed(rl.KeyRight) {
+		gs.howToPlayPage = min(gs.howToPlayPage+1, len(howToPlayPages)-1)
+	}
+	if rl.IsKeyPressed(rl.KeyLeft) {
+		gs.howToPlayPage = max(gs.howToPlayPage-1, 0)
 	}
 
 	x := int32(gs.boundaries.screenBoundaries.Width / 2)
 	y := int32(gs.boundaries.screenBoundaries.Y + 10)
-	howToPlayTitle := "How to play"
+	howToPlayTitle := fmt.Sprintf("How to play (%d/%d)", gs.howToPlayPage+1, len(howToPlayPages))
 	howToPlayTitleWidth := rl.MeasureText(howToPlayTitle, 50)
 	rl.DrawText(howToPlayTitle, x-howToPlayTitleWidth/2, y, 50, rl.White)
 	y += 60
 
-	lineX := x - 300
+	lineX := x - 360
 
-	for _, line := range tutorialText {
+	for _, line := range howToPlayPages[gs.howToPlayPage] {
 		rl.DrawText(line, lineX, y, 20, rl.White)
 		y += 30
 	}
 
 	// Add a back button to return to the main menu
-	backButton := "Click anywhere to return to the main menu"
+	backButton := "Turn pages with the arrow keys, click anywhere to return to the main menu"
 	backButtonWidth := rl.MeasureText(backButton, 20)
 	rl.DrawText(backButton, x-backButtonWidth/2, y+20, 20, rl.Gray) // Draw back button text
 	if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+		gs.howToPlayPage = 0
 		gs.gameScreen = gameScreenMainMenu // Return to main menu on click
 	}
 }
@@ -932,6 +1509,9 @@ func (gs *gameState) useConsumable() {
 }
 
 func (gs *gameState) useFlare() {
+	if gs.contracts.Has(contract.Blackout) {
+		return
+	}
 	if rl.IsMouseButtonPressed(rl.MouseLeftButton) &&
 		// gs.draggingSoldier == nil &&
 		gs.itemStorage.flareCount > 0 {
@@ -942,29 +1522,13 @@ func (gs *gameState) useFlare() {
 		newFlare := flare.FromPos(mousePos)
 		gs.flares = append(gs.flares, newFlare)
 		gs.itemStorage.flareCount--
+		events.Publish(gs.events, events.ConsumableUsed{Name: "Flare", Pos: mousePos})
 	}
 }
 
 func (gs *gameState) processFlares() {
-	wentOutCount := 0
-	for _, f := range gs.flares {
-		f.Dim()
-		if f.WentOut() {
-			wentOutCount++
-			continue
-		}
-		gs.quadtree.Insert(f.ID, f.Boundaries(), f)
-	}
-
-	if wentOutCount > 0 {
-		gs.flares = gs.flares[wentOutCount:]
-	}
-}
-
-func (gs *gameState) renderFlares() {
-	for _, f := range gs.flares {
-		f.Draw()
-	}
+	gs.flares = ecs.Age(gs.flares, rl.GetFrameTime())
+	ecs.Collide(gs.quadtree, gs.flares)
 }
 
 func (gs *gameState) useGrenade() {
@@ -975,85 +1539,157 @@ func (gs *gameState) useGrenade() {
 		if !rl.CheckCollisionPointRec(mousePos, gs.boundaries.arenaBoundaries) {
 			return
 		}
-		newGrenade := grenade.FromPos(mousePos)
+		newGrenade := grenade.FromPos(mousePos, gs.balance.Effects.Grenade)
 		gs.grenades = append(gs.grenades, newGrenade)
 		gs.itemStorage.grenadeCount--
+		events.Publish(gs.events, events.ConsumableUsed{Name: "Grenade", Pos: mousePos})
 	}
 }
 
 func (gs *gameState) processGrenades() {
-	activeGrenades := gs.grenades[:0]
-	for _, g := range gs.grenades {
-		g.ProgressTime(rl.GetFrameTime())
-		if !g.Active() {
-			continue
-		}
-		activeGrenades = append(activeGrenades, g)
-		gs.quadtree.Insert(g.ID, g.Boundaries(), g)
-	}
-	gs.grenades = activeGrenades
-}
-
-func (gs *gameState) renderGrenades() {
-	for _, g := range gs.grenades {
-		g.Draw()
-	}
+	gs.grenades = ecs.Age(gs.grenades, rl.GetFrameTime())
+	ecs.Collide(gs.quadtree, gs.grenades)
 }
 
 func (gs *gameState) processProjectiles() {
-	activeProjectiles := gs.projectiles[:0]
+	ecs.Move(gs.projectiles)
 	for _, p := range gs.projectiles {
-		p.Move()
-		if !rl.CheckCollisionPointRec(p.Pos, gs.boundaries.arenaBoundaries) || p.Expired {
-			continue
+		gs.hitFirstTargetOnPath(p)
+		if !rl.CheckCollisionPointRec(p.Pos, gs.boundaries.arenaBoundaries) {
+			p.Expire()
 		}
-		gs.quadtree.Insert(p.ID, p.Boundaries(), p)
-		activeProjectiles = append(activeProjectiles, p)
 	}
-	gs.projectiles = activeProjectiles
+	gs.projectiles = ecs.Age(gs.projectiles, rl.GetFrameTime())
+	ecs.Collide(gs.quadtree, gs.projectiles)
 }
 
-func (gs *gameState) renderProjectiles() {
-	for _, p := range gs.projectiles {
-		p.Draw()
+// hitFirstTargetOnPath checks the whole path projectile travelled during this frame,
+// so fast projectiles can't tunnel through enemies, soldiers or obstacles
+func (gs *gameState) hitFirstTargetOnPath(p *projectile.Projectile) {
+	if p.Expired() {
+		return
+	}
+	// enemies and soldiers didn't move yet, so we can use previous quadtree
+	hits := gs.prevQuadtree.QuerySweptCircle(p.PrevPos, p.Pos, p.Radius)
+	for _, h := range hits {
+		switch val := h.Value.(type) {
+		case enemies.Enemy:
+			if !p.Hits(ecs.TeamEnemies) || val.IsDead() {
+				continue
+			}
+			damageEnemy(val, p.DealDamage())
+			p.Expire()
+			if val.IsDead() {
+				p.Credit(gs.reward(val.Reward()))
+			}
+			return
+		case *soldier.Soldier:
+			if !p.Hits(ecs.TeamSoldiers) || val.IsDead() {
+				continue
+			}
+			gs.damageSoldier(val, p.DealDamage())
+			p.Expire()
+			return
+		case *vip.VIP:
+			if !p.Hits(ecs.TeamSoldiers) || val.IsDead() {
+				continue
+			}
+			val.TakeDamage(p.DealDamage())
+			p.Expire()
+			return
+		case *obstacle.Obstacle:
+			p.Expire()
+			return
+		}
 	}
 }
 
-const (
-	halfMaxInt    = math.MaxInt / 2
-	quorterMaxInt = halfMaxInt / 2
-	tenthMaxInt   = math.MaxInt / 10
-)
+func newEnemyRegistry(assets *gameAssets, b *balance.Balance) *enemies.Registry[enemies.Enemy] {
+	registry := &enemies.Registry[enemies.Enemy]{}
+	newType := func(name string, newEnemy func(pos rl.Vector2, time float32, stats balance.Enemy) enemies.Enemy) enemies.Type[enemies.Enemy] {
+		stats := b.Enemies[name]
+		return enemies.Type[enemies.Enemy]{
+			Name: name,
+			New: func(pos rl.Vector2, time float32) enemies.Enemy {
+				return newEnemy(pos, time, stats)
+			},
+			Pack:     stats.Spawn.Pack,
+			Weight:   enemies.SpawnWeight(stats.Spawn),
+			UnlockAt: stats.Spawn.UnlockAt,
+		}
+	}
+	register := func(name string, newEnemy func(pos rl.Vector2, time float32, stats balance.Enemy) enemies.Enemy) {
+		registry.Register(newType(name, newEnemy))
+	}
+	// registerPack registers type which pack members share state
+	registerPack := func(name string, newPack func(positions []rl.Vector2, time float32, stats balance.Enemy) []enemies.Enemy) {
+		t := newType(name, func(pos rl.Vector2, time float32, stats balance.Enemy) enemies.Enemy {
+			return newPack([]rl.Vector2{pos}, time, stats)[0]
+		})
+		stats := b.Enemies[name]
+		t.Group = func(positions []rl.Vector2, time float32) []enemies.Enemy {
+			return newPack(positions, time, stats)
+		}
+		registry.Register(t)
+	}
 
-var (
-	basicRange = [2]int{0, halfMaxInt}                                        // 0 - 50%
-	fastRange  = [2]int{halfMaxInt, halfMaxInt + quorterMaxInt + tenthMaxInt} // 50% - 85%
-	// tank is default case (15%)
-)
+	register(basic.Name, func(pos rl.Vector2, time float32, stats balance.Enemy) enemies.Enemy {
+		return basic.FromPos(pos, assets.enemy.basic, time, stats)
+	})
+	register(fast.Name, func(pos rl.Vector2, time float32, stats balance.Enemy) enemies.Enemy {
+		return fast.FromPos(pos, assets.enemy.fast, time, stats)
+	})
+	register(tank.Name, func(pos rl.Vector2, time float32, stats balance.Enemy) enemies.Enemy {
+		return tank.FromPos(pos, assets.enemy.tank, time, stats)
+	})
+	register(spitter.Name, func(pos rl.Vector2, time float32, stats balance.Enemy) enemies.Enemy {
+		return spitter.FromPos(pos, assets.enemy.spitter, time, stats)
+	})
+	register(splitter.Name, func(pos rl.Vector2, time float32, stats balance.Enemy) enemies.Enemy {
+		return splitter.FromPos(pos, assets.enemy.splitter, time, stats, func(pos rl.Vector2) enemies.Enemy {
+			child, _ := registry.Get(stats.Split.Into) // balance validation guarantees it exists
+			return child.New(pos, time)
+		})
+	})
+	register(splitter.ChildName, func(pos rl.Vector2, time float32, stats balance.Enemy) enemies.Enemy {
+		return splitter.ChildFromPos(pos, assets.enemy.splitling, time, stats)
+	})
+	register(lighteater.Name, func(pos rl.Vector2, time float32, stats balance.Enemy) enemies.Enemy {
+		return lighteater.FromPos(pos, assets.enemy.lighteater, time, stats)
+	})
+	registerPack(swarm.Name, func(positions []rl.Vector2, time float32, stats balance.Enemy) []enemies.Enemy {
+		members := swarm.NewPack(positions, assets.enemy.swarm, time, stats).Members()
+		pack := make([]enemies.Enemy, 0, len(members))
+		for _, m := range members {
+			pack = append(pack, m)
+		}
+		return pack
+	})
 
-func inRange(x int, r [2]int) bool {
-	return x >= r[0] && x < r[1]
+	return registry
 }
 
-func newEnemy(pos rl.Vector2, assets *gameAssets, time float32) enemy {
-	n := rand.Intn(math.MaxInt)
-	switch {
-	case inRange(n, basicRange):
-		return basic.FromPos(pos, assets.enemy.basic, time)
-	case inRange(n, fastRange):
-		return fast.FromPos(pos, assets.enemy.fast, time)
-	default:
-		return tank.FromPos(pos, assets.enemy.tank, time)
+func (gs *gameState) spawnEnemies() {
+	if gs.waves != nil {
+		gs.spawnWaves()
+		return
 	}
-}
+	if gs.retreating() {
+		return
+	}
+	gs.spawnBoss()
 
-func (gs *gameState) spawnEnemies() {
 	gs.enemeSpawnedAgo += rl.GetFrameTime()
 
-	multiplier := gs.gameTime / 60
-	spawnRate := gs.spawnRate * float32(math.Pow(0.90, float64(multiplier)))
-	if spawnRate < spawnRateLimit {
-		spawnRate = spawnRateLimit
+	spawnRate := gs.director.Update(rl.GetFrameTime(), director.Snapshot{
+		Time:          gs.gameTime,
+		SoldierHealth: gs.soldierHealth(),
+		FlareCoverage: gs.flareCoverage(),
+		BaseInterval:  gs.baseSpawnRate(),
+		MinInterval:   gs.balance.SpawnRateLimit,
+	})
+	if gs.contracts.Has(contract.Frenzy) {
+		spawnRate /= gs.balance.Contracts.FrenzySpawns
 	}
 	if gs.enemeSpawnedAgo < spawnRate {
 		return
@@ -1061,161 +1697,873 @@ func (gs *gameState) spawnEnemies() {
 
 	gs.enemeSpawnedAgo = 0
 
-	newEnemy, ok := gs.spawnEnemy()
-	if !ok {
+	if pack := gs.spawnEnemy(); pack != nil {
+		gs.telegraph(pack)
+	}
+}
+
+// pendingSpawn is a pack that appears once its telegraph runs out
+type pendingSpawn struct {
+	telegraph spawnzone.Telegraph
+	pack      []enemies.Enemy
+}
+
+// telegraph marks place of the pack, so players have time to react before it spawns
+func (gs *gameState) telegraph(pack []enemies.Enemy) {
+	area := pack[0].Boundaries()
+	for _, e := range pack[1:] {
+		area = rlutils.RectanglesUnion(area, e.Boundaries())
+	}
+	gs.pending = append(gs.pending, &pendingSpawn{
+		telegraph: spawnzone.NewTelegraph(area),
+		pack:      pack,
+	})
+}
+
+// progressTelegraphs spawns packs which telegraphs ran out.
+// Pack waits while its place is taken, watched by soldiers or lit by flares.
+func (gs *gameState) progressTelegraphs(dt float32) {
+	pending := gs.pending[:0]
+	for _, p := range gs.pending {
+		p.telegraph.Progress(dt)
+		if p.telegraph.Ready() && gs.canSpawnPack(p.pack) {
+			gs.addSpawned(p.pack)
+			continue
+		}
+		pending = append(pending, p)
+	}
+	gs.pending = pending
+}
+
+// enemiesLeft counts alive enemies and those that are about to spawn
+func (gs *gameState) enemiesLeft() int {
+	left := len(gs.enemies)
+	for _, p := range gs.pending {
+		left += len(p.pack)
+	}
+	return left
+}
+
+// dawnColor tints the arena as the sky brightens before dawn
+var dawnColor = rl.Color{R: 255, G: 170, B: 100, A: 255}
+
+func (gs *gameState) renderSky() {
+	if gs.objective == nil {
 		return
 	}
-	gs.enemies = append(gs.enemies, newEnemy)
+	if daylight := gs.objective.Daylight(gs.gameTime); daylight > 0 {
+		rl.DrawRectangleRec(gs.boundaries.arenaBoundaries, rl.Fade(dawnColor, 0.3*daylight))
+	}
 }
 
-func (gs *gameState) spawnEnemy() (enemy, bool) {
-	arenaBoundaries := gs.boundaries.arenaBoundaries
+func (gs *gameState) renderNests() {
+	for _, z := range gs.zones {
+		z.DrawNest()
+	}
+}
+
+func (gs *gameState) renderTelegraphs() {
+	for _, p := range gs.pending {
+		p.telegraph.Draw()
+	}
+}
+
+// addSpawned adds spawned enemies to the arena, some of them become elites.
+// During elite wave contract all of them do, even before elites unlock.
+func (gs *gameState) addSpawned(pack []enemies.Enemy) {
+	elite := gs.balance.Elite
+	if gs.contracts.Has(contract.Elites) {
+		elite.UnlockAt, elite.Chance = 0, 1
+	}
+	for _, e := range pack {
+		if _, ok := e.(*boss.Boss); !ok {
+			e.MakeElite(enemies.RollAffixes(gs.gameTime, elite), gs.gameTime, elite)
+		}
+		gs.enemies = append(gs.enemies, e)
+		e.OnSpawn()
+	}
+}
+
+// spawnWaves telegraphs enemies when wave script says so, wave mode is won once the last wave is cleared
+func (gs *gameState) spawnWaves() {
+	gs.waves.Update(rl.GetFrameTime(), gs.enemiesLeft())
+	if gs.waves.State() == waves.Done {
+		gs.endRun(true)
+		return
+	}
+
+	for g, ok := gs.waves.Next(); ok; g, ok = gs.waves.Next() {
+		pack := gs.spawnGroup(g)
+		if pack == nil {
+			// no free place, try again next frame
+			return
+		}
+		gs.telegraph(pack)
+		gs.waves.Spawned()
+	}
+}
+
+// zoneSpawnAttempts is how many random places in spawn zones are tried every frame
+const zoneSpawnAttempts = 10
+
+// spawnGroup creates enemy of the group in one of its zones, nil if no free place was found
+func (gs *gameState) spawnGroup(g waves.Group) []enemies.Enemy {
+	for range zoneSpawnAttempts {
+		if g.Enemy == boss.Name {
+			texture := gs.assets.enemy.boss
+			pos := gs.zonePosition(g.ZoneWeight, float32(max(texture.Width, texture.Height)))
+			b := gs.newBoss(pos)
+			if gs.canSpawnHere([]enemies.Enemy{b}) {
+				return []enemies.Enemy{b}
+			}
+			continue
+		}
+
+		t, _ := gs.enemyRegistry.Get(g.Enemy) // wave file validation guarantees it exists
+		size := max(t.Pack, 1)
+		pos := gs.zonePosition(g.ZoneWeight, packExtent(size))
+		pack := t.Spawn(packPositions(pos, size), gs.gameTime)
+		if gs.canSpawnHere(pack) {
+			return pack
+		}
+	}
+	return nil
+}
+
+// zonePosition returns random position for square of given size in a spawn zone picked by weight.
+// Zones are equally likely if none of them has weight, e.g. only nests are weighted and arena has none.
+func (gs *gameState) zonePosition(weight func(spawnzone.Zone) float32, size float32) rl.Vector2 {
+	z, ok := spawnzone.Roll(gs.zones, weight)
+	if !ok {
+		z = gs.zones[rand.Intn(len(gs.zones))]
+	}
+	return z.RandomPos(size)
+}
+
+// directorZoneWeight weights spawn zones by current phase of director
+func (gs *gameState) directorZoneWeight(z spawnzone.Zone) float32 {
+	return spawnzone.Weight(z, gs.director.ZoneWeights())
+}
+
+// baseSpawnRate is seconds between spawns without director, it goes down with game time
+func (gs *gameState) baseSpawnRate() float32 {
+	multiplier := gs.gameTime / 60
+	spawnRate := gs.spawnRate * float32(math.Pow(0.90, float64(multiplier)))
+	return max(spawnRate, gs.balance.SpawnRateLimit)
+}
+
+// soldierHealth is part of health soldiers have left, soldiers lost in the run count as empty
+func (gs *gameState) soldierHealth() float32 {
+	var hp, maxHP float32
+	for _, s := range gs.soldiers {
+		hp += max(s.HP, 0)
+		maxHP += s.MaxHP
+	}
+	if maxHP == 0 {
+		return 0
+	}
+	health := hp / maxHP
+	if soldierCount > len(gs.soldiers) {
+		health *= float32(len(gs.soldiers)) / float32(soldierCount)
+	}
+	return health
+}
+
+// flareCoverage is part of arena cells lit by flares
+func (gs *gameState) flareCoverage() float32 {
+	if len(gs.flares) == 0 {
+		return 0
+	}
+	lit := 0
+	for y := range gs.navGrid.Rows() {
+		for x := range gs.navGrid.Cols() {
+			if gs.cellLight(navgrid.Cell{X: x, Y: y}) > 0 {
+				lit++
+			}
+		}
+	}
+	return float32(lit) / float32(gs.navGrid.Rows()*gs.navGrid.Cols())
+}
+
+// spawnEnemy spawns a pack of enemies of random type, most types come in packs of one
+func (gs *gameState) spawnEnemy() []enemies.Enemy {
+	t, ok := gs.enemyRegistry.Roll(gs.gameTime)
+	if !ok {
+		// no enemy type is unlocked yet
+		return nil
+	}
+
+	size := max(t.Pack, 1)
 	attempt := 0
 	for {
 		attempt++
 		if attempt > 100 {
-			gs.victory = true
-			gs.gameScreen = gameScreenOver
-			return nil, false
+			// arena is too crowded, try again next time
+			return nil
 		}
-		// should be spawned in arena boundaries
-		pos := rl.Vector2{
-			// between arena X and X + Width
-			X: float32(rand.Intn(int(arenaBoundaries.Width))) + arenaBoundaries.X,
-			// between arena Y and Y + Height
-			Y: float32(rand.Intn(int(arenaBoundaries.Height))) + arenaBoundaries.Y,
+		pos := gs.zonePosition(gs.directorZoneWeight, packExtent(size))
+		pack := t.Spawn(packPositions(pos, size), gs.gameTime)
+		if gs.canSpawnHere(pack) {
+			return pack
 		}
+	}
+}
 
-		if gs.anySoldierCanShoot(pos) {
-			continue
+// packSize is how many enemies of given type are spawned together
+func (gs *gameState) packSize(enemy string) int {
+	t, ok := gs.enemyRegistry.Get(enemy)
+	if !ok {
+		// boss comes alone
+		return 1
+	}
+	return max(t.Pack, 1)
+}
+
+// packSpacing is distance between pack members, so they don't overlap
+const packSpacing = 45
+
+// packExtent is width and height of the square pack takes
+func packExtent(size int) float32 {
+	return float32(math.Ceil(math.Sqrt(float64(size)))) * packSpacing
+}
+
+// packPositions places pack members in a square grid starting at pos
+func packPositions(pos rl.Vector2, size int) []rl.Vector2 {
+	columns := int(math.Ceil(math.Sqrt(float64(size))))
+	positions := make([]rl.Vector2, 0, size)
+	for i := range size {
+		positions = append(positions, rl.Vector2{
+			X: pos.X + float32(i%columns)*packSpacing,
+			Y: pos.Y + float32(i/columns)*packSpacing,
+		})
+	}
+	return positions
+}
+
+// canSpawnHere checks that pack can spawn and isn't already telegraphed there
+func (gs *gameState) canSpawnHere(pack []enemies.Enemy) bool {
+	if !gs.canSpawnPack(pack) {
+		return false
+	}
+	for _, p := range gs.pending {
+		for _, e := range pack {
+			if rl.CheckCollisionRecs(e.Boundaries(), p.telegraph.Area) {
+				return false
+			}
 		}
+	}
+	return true
+}
 
-		newEnemy := newEnemy(pos, gs.assets, gs.gameTime)
+// canSpawnPack checks that every member is in arena, out of shooting range, out of flare light and has free place
+func (gs *gameState) canSpawnPack(pack []enemies.Enemy) bool {
+	for _, e := range pack {
+		if gs.anySoldierCanShoot(e.GetPos()) {
+			return false
+		}
+		if !rl.CheckCollisionPointRec(e.GetPos(), gs.boundaries.arenaBoundaries) {
+			return false
+		}
+		if gs.litByFlare(e.Boundaries()) {
+			return false
+		}
+		if !gs.canSpawnAt(e.Boundaries()) {
+			return false
+		}
+	}
+	return true
+}
 
-		collissions := gs.prevQuadtree.Query(newEnemy.Boundaries())
-		if len(collissions) == 0 {
-			return newEnemy, true
+func (gs *gameState) litByFlare(boundaries rl.Rectangle) bool {
+	for _, f := range gs.flares {
+		if rl.CheckCollisionCircleRec(f.Pos, f.Radius, boundaries) {
+			return true
 		}
 	}
+	return false
+}
+
+// canSpawnAt checks that the place is not covered by obstacles
+// and nothing but dead enemies occupied it last frame
+func (gs *gameState) canSpawnAt(boundaries rl.Rectangle) bool {
+	if gs.blockedByObstacles(boundaries) {
+		return false
+	}
+	for _, c := range gs.prevQuadtree.Query(boundaries) {
+		if e, ok := c.Value.(enemies.Enemy); ok && e.IsDead() {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// addSummoned adds enemies summoned by other enemies, if there is place for them
+func (gs *gameState) addSummoned(summoned []enemies.Enemy) {
+	for _, e := range summoned {
+		if !rl.CheckCollisionPointRec(e.GetPos(), gs.boundaries.arenaBoundaries) {
+			continue
+		}
+		if !gs.canSpawnAt(e.Boundaries()) {
+			continue
+		}
+		gs.enemies = append(gs.enemies, e)
+		e.OnSpawn()
+	}
+}
+
+// spawnBoss telegraphs boss once game time reaches nextBossAt,
+// if there is no free place for it, it tries again next frame
+func (gs *gameState) spawnBoss() {
+	if gs.gameTime < gs.nextBossAt {
+		return
+	}
+
+	texture := gs.assets.enemy.boss
+	// boss is big, so whole texture should fit in arena
+	pos := gs.zonePosition(gs.directorZoneWeight, float32(max(texture.Width, texture.Height)))
+	b := gs.newBoss(pos)
+	if !gs.canSpawnHere([]enemies.Enemy{b}) {
+		return
+	}
+
+	gs.nextBossAt += gs.balance.Boss.Every
+	gs.telegraph([]enemies.Enemy{b})
+}
+
+func (gs *gameState) newBoss(pos rl.Vector2) *boss.Boss {
+	return boss.FromPos(pos, gs.assets.enemy.boss, gs.gameTime, gs.balance.Boss, func(pos rl.Vector2) enemies.Enemy {
+		return basic.FromPos(pos, gs.assets.enemy.basic, gs.gameTime, gs.balance.Enemies[basic.Name])
+	})
 }
 
 func (gs *gameState) anySoldierCanShoot(pos rl.Vector2) bool {
 	for _, s := range gs.soldiers {
-		if s.WithinShootingRange(pos) {
+		if s.WithinShootingRange(pos) && gs.lineOfSight(s.Pos, pos) {
 			return true
 		}
 	}
 	return false
 }
 
-func (gs *gameState) processEnemies() []enemy {
-	flaredEnemies := make([]enemy, 0, len(gs.enemies)/3)
-	for _, e := range gs.enemies {
-		nearestSoldier := findNearest(gs.soldiers, e.GetPos())
-		newPosition := e.MoveTowards(nearestSoldier.Pos)
+// lineOfSight reports whether no obstacle stands between two points
+func (gs *gameState) lineOfSight(from, to rl.Vector2) bool {
+	return gs.navGrid.LineOfSight(from, to)
+}
 
-		// soldiers didn't move yet, so we can use previous quadtree
-		soldierCollissions := gs.prevQuadtree.Query(e.Boundaries())
+func (gs *gameState) blockedByObstacles(rect rl.Rectangle) bool {
+	return gs.navGrid.Overlaps(rect)
+}
+
+// enemyWorkers is how many goroutines compute enemy intents.
+// Small hordes are processed on the calling goroutine, since spawning workers costs more than it saves.
+var enemyWorkers = runtime.GOMAXPROCS(0)
+
+const minEnemiesPerWorker = 64
+
+// enemyIntent is what enemy is going to do this frame.
+// Intents are computed without mutating game state, so they can be computed in parallel,
+// and then applied one by one in enemies order, so result doesn't depend on number of workers.
+type enemyIntent struct {
+	newPosition rl.Vector2
+	target      rl.Vector2 // position of what enemy goes to
+	eaten       []*flare.Flare
+	flared      bool
+	// sorted by ID, so order doesn't depend on quadtree internals
+	soldierCollisions []quadtree.Data
+	collisions        []quadtree.Data
+	hitVIP            *vip.VIP // nil if enemy doesn't touch VIP
+}
 
-		for _, c := range soldierCollissions {
-			if soldier, ok := c.Value.(*soldier.Soldier); ok {
-				soldier.Health -= e.DealDamage()
-				newPosition = e.GetPos() // don't move if collission
+func (gs *gameState) processEnemies() []enemies.Enemy {
+	workers := enemyWorkers
+	if len(gs.enemies) < minEnemiesPerWorker*2 {
+		workers = 1
+	}
+	return gs.processEnemiesWith(workers)
+}
+
+func (gs *gameState) processEnemiesWith(workers int) []enemies.Enemy {
+	gs.soldierField = gs.buildSoldierField()
+	intents := gs.computeEnemyIntents(workers)
+
+	flaredEnemies := make([]enemies.Enemy, 0, len(gs.enemies)/3)
+	var summoned []enemies.Enemy
+	for i, e := range gs.enemies {
+		intent := intents[i]
+		newPosition := intent.newPosition
+
+		if t, ok := e.(enemies.Timed); ok {
+			t.ProgressTime(rl.GetFrameTime())
+		}
+
+		status := e.GetStatus()
+		if burn := status.ProgressEffects(rl.GetFrameTime()); burn > 0 {
+			damageEnemy(e, burn)
+		}
+		stunned := status.Stunned()
+
+		if !stunned {
+			for _, c := range intent.soldierCollisions {
+				gs.damageSoldier(c.Value.(*soldier.Soldier), e.DealDamage())
+				e.OnHit(e.DealDamage())
+			}
+			if intent.hitVIP != nil {
+				intent.hitVIP.TakeDamage(e.DealDamage())
+				e.OnHit(e.DealDamage())
 			}
 		}
 
-		flared := false
-		collissions := gs.quadtree.Query(e.Boundaries())
-		for _, c := range collissions {
+		for _, c := range intent.collisions {
 			switch val := c.Value.(type) {
-			case *flare.Flare:
-				flared = true
-				// Try to move away from flare
-				newPosition = e.MoveAway(val.Pos)
 			case *projectile.Projectile:
-				if !val.Expired {
-					e.TakeDamage(val.Damage)
-					val.Expired = true
+				if !val.Expired() {
+					damageEnemy(e, val.DealDamage())
+					val.Expire()
 				}
 				if e.IsDead() {
-					val.Shooter.EarnExp(reward(e.Reward()))
+					val.Credit(gs.reward(e.Reward()))
 				}
 			case *grenade.Grenade:
-				e.TakeDamage(val.Damage)
+				val.ApplyTo(status)
 			}
 		}
 
+		if le, ok := e.(enemies.LightEater); ok {
+			for _, f := range intent.eaten {
+				f.Drain(le.Drain())
+			}
+		}
+
+		if s, ok := e.(enemies.Summoner); ok {
+			// summoned even if summoner died from the hit that summoned them
+			summoned = append(summoned, s.Summoned()...)
+		}
+
 		if e.IsDead() {
 			continue
 		}
 
-		if flared {
+		if intent.flared {
 			flaredEnemies = append(flaredEnemies, e)
 		}
 
 		e.UpdatePosition(newPosition)
 		gs.quadtree.Insert(e.GetID(), e.Boundaries(), e)
+
+		if r, ok := e.(enemies.Ranged); ok && !stunned {
+			if p, ok := r.Shoot(intent.target, rl.GetFrameTime()); ok {
+				gs.projectiles = append(gs.projectiles, p)
+			}
+		}
 	}
 
+	gs.addSummoned(summoned)
+
 	return flaredEnemies
 }
 
+// computeEnemyIntents splits enemies into contiguous shards, one per worker.
+// Workers only read game state and each one writes to its own part of intents.
+func (gs *gameState) computeEnemyIntents(workers int) []enemyIntent {
+	intents := make([]enemyIntent, len(gs.enemies))
+	if workers <= 1 {
+		for i, e := range gs.enemies {
+			intents[i] = gs.computeEnemyIntent(e)
+		}
+		return intents
+	}
+
+	shardSize := (len(gs.enemies) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(gs.enemies); start += shardSize {
+		end := min(start+shardSize, len(gs.enemies))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				intents[i] = gs.computeEnemyIntent(gs.enemies[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return intents
+}
+
+func (gs *gameState) computeEnemyIntent(e enemies.Enemy) enemyIntent {
+	var intent enemyIntent
+
+	boundaries := e.Boundaries()
+	center := rlutils.RectangleCenter(boundaries)
+	size := max(boundaries.Width, boundaries.Height)
+	// slows and stuns were applied last frame, so it's safe to read them concurrently
+	slowdown := e.GetStatus().SpeedMultiplier()
+	speed := e.GetVelocity().Speed * slowdown
+	var forces steering.Forces
+
+	intent.target = gs.enemyTarget(e)
+	if f, ok := e.(enemies.Flock); ok && f.Leads() {
+		f.Lead(gs.packHeading(e, center, intent.target))
+	}
+	if gs.retreating() {
+		forces.Seek = steering.Arrive(center, gs.retreatPoint(center), speed, 0)
+	} else if newPosition, ok := e.CustomMove(intent.target); ok {
+		forces.Seek = rl.Vector2Scale(rl.Vector2Subtract(newPosition, e.GetPos()), slowdown)
+		// custom moves like charging can be faster than usual
+		speed = max(speed, rl.Vector2Length(forces.Seek))
+	} else if dir, ok := gs.followSoldierField(e, center); ok {
+		forces.Seek = rl.Vector2Scale(dir, speed)
+	} else if waypoint, ok := gs.navigate(e, center, intent.target); ok {
+		// don't slow down at waypoints, only at the target itself
+		forces.Seek = steering.Arrive(e.GetPos(), waypoint, speed, 0)
+	} else {
+		forces.Seek = steering.Arrive(e.GetPos(), intent.target, speed, e.Steering().Arrival)
+	}
+
+	// soldiers and enemies didn't move yet, so we can use previous quadtree
+	var neighbours []steering.Neighbour
+	neighbourhood := rl.Rectangle{X: center.X - size, Y: center.Y - size, Width: size * 2, Height: size * 2}
+	for _, c := range sortedByID(gs.prevQuadtree.Query(neighbourhood)) {
+		switch val := c.Value.(type) {
+		case *soldier.Soldier:
+			if rl.CheckCollisionRecs(boundaries, val.Boundaries()) {
+				intent.soldierCollisions = append(intent.soldierCollisions, c)
+			}
+		case *vip.VIP:
+			if !val.IsDead() && rl.CheckCollisionRecs(boundaries, val.Boundaries()) {
+				intent.hitVIP = val
+			}
+		case enemies.Enemy:
+			if !val.IsDead() {
+				neighbours = append(neighbours, steering.Neighbour{ID: val.GetID(), Pos: rlutils.RectangleCenter(val.Boundaries())})
+			}
+		}
+	}
+	if len(intent.soldierCollisions) > 0 || intent.hitVIP != nil {
+		// stay and fight, only flares and crowd can make enemy move
+		forces.Seek = rl.Vector2{}
+	}
+	forces.Separation = steering.Separate(steering.Neighbour{ID: e.GetID(), Pos: center}, neighbours, speed, size)
+
+	for _, c := range sortedByID(gs.quadtree.Query(boundaries)) {
+		switch val := c.Value.(type) {
+		case *flare.Flare:
+			intent.flared = true
+			if _, ok := e.(enemies.LightEater); ok {
+				intent.eaten = append(intent.eaten, val)
+				break
+			}
+			if fi, ok := e.(enemies.FlareImmune); ok && fi.IgnoresFlares() {
+				break
+			}
+			flee := steering.Flee(center, val.Pos, speed, val.Radius+size/2)
+			forces.Flee = rl.Vector2Add(forces.Flee, flee)
+		case *projectile.Projectile:
+			if val.Hits(ecs.TeamEnemies) {
+				intent.collisions = append(intent.collisions, c)
+			}
+		case *grenade.Grenade:
+			intent.collisions = append(intent.collisions, c)
+		}
+	}
+
+	move := forces.Blend(e.Steering(), speed)
+	move = gs.navGrid.Slide(boundaries, move)
+	intent.newPosition = rl.Vector2Add(e.GetPos(), move)
+
+	return intent
+}
+
+// buildSoldierField computes distance to the nearest soldier once for the whole horde.
+// Flare light makes cells expensive, so enemies walk around flares when detour is short enough.
+func (gs *gameState) buildSoldierField() *navgrid.Field {
+	goals := make([]rl.Vector2, 0, len(gs.soldiers))
+	for _, s := range gs.soldiers {
+		goals = append(goals, rlutils.RectangleCenter(s.Boundaries()))
+	}
+	return gs.navGrid.Field(goals, gs.flareCost)
+}
+
+// flareCost is extra cost of walking through the cell, the brighter the cell is lit, the more it costs
+func (gs *gameState) flareCost(c navgrid.Cell) float64 {
+	if len(gs.flares) == 0 {
+		return 0
+	}
+	return float64(gs.cellLight(c) * gs.balance.Arena.FlareCost)
+}
+
+// cellLight is how bright the center of the cell is lit by the brightest flare, 0 to 1
+func (gs *gameState) cellLight(c navgrid.Cell) float32 {
+	center := gs.navGrid.Center(c)
+	var light float32
+	for _, f := range gs.flares {
+		if dist := rl.Vector2Distance(center, f.Pos); dist < f.Radius {
+			light = max(light, 1-dist/f.Radius)
+		}
+	}
+	return light
+}
+
+// followSoldierField returns direction to the nearest soldier down the soldier field.
+// Light eaters don't follow it, since they walk to flares, and neither do enemies that chase VIP.
+// Enemies in the same cell as soldier walk to it directly.
+func (gs *gameState) followSoldierField(e enemies.Enemy, center rl.Vector2) (rl.Vector2, bool) {
+	if _, ok := e.(enemies.LightEater); ok {
+		return rl.Vector2{}, false
+	}
+	if gs.chasesVIP(e) {
+		return rl.Vector2{}, false
+	}
+	return gs.soldierField.Direction(center)
+}
+
+// packHeading is direction pack leader takes the pack to: down the soldier field,
+// to the next waypoint around obstacles or straight to the target
+func (gs *gameState) packHeading(e enemies.Enemy, center, target rl.Vector2) rl.Vector2 {
+	if dir, ok := gs.followSoldierField(e, center); ok {
+		return dir
+	}
+	if waypoint, ok := gs.navigate(e, center, target); ok {
+		target = waypoint
+	}
+	return rl.Vector2Normalize(rl.Vector2Subtract(target, e.GetPos()))
+}
+
+// navigate returns waypoint enemy should walk to, when obstacles stand between it and target.
+// Route is rebuilt once target moves to another cell.
+// It changes only the route of the enemy, so it's safe to call concurrently for different enemies.
+func (gs *gameState) navigate(e enemies.Enemy, center, target rl.Vector2) (rl.Vector2, bool) {
+	route := e.GetRoute()
+	// route is built for enemy center, target is where top left corner should end up
+	offset := rl.Vector2Subtract(center, e.GetPos())
+	goal := rl.Vector2Add(target, offset)
+	if gs.navGrid.LineOfSight(center, goal) {
+		route.Clear()
+		return rl.Vector2{}, false
+	}
+
+	if _, ok := route.Next(); !ok || gs.navGrid.CellAt(route.Goal) != gs.navGrid.CellAt(goal) {
+		route.Waypoints = gs.navGrid.Path(center, goal)
+		route.Goal = goal
+	}
+	for len(route.Waypoints) > 1 {
+		next := route.Waypoints[0]
+		reached := rl.Vector2Distance(center, next) < gs.navGrid.CellSize/2
+		if !reached && !gs.navGrid.LineOfSight(center, route.Waypoints[1]) {
+			break
+		}
+		route.Advance()
+	}
+
+	waypoint, ok := route.Next()
+	if !ok {
+		// target can't be reached, walk straight and slide along obstacles
+		return rl.Vector2{}, false
+	}
+	return rl.Vector2Subtract(waypoint, offset), true
+}
+
+// enemyTarget is position enemy walks to: VIP, the nearest soldier,
+// or the nearest flare for enemies that eat light
+func (gs *gameState) enemyTarget(e enemies.Enemy) rl.Vector2 {
+	if _, ok := e.(enemies.LightEater); ok {
+		if f := findNearest(gs.flares, e.GetPos()); f != nil {
+			return f.Pos
+		}
+	}
+	if gs.chasesVIP(e) {
+		return gs.vip.Pos
+	}
+	return findNearest(gs.soldiers, e.GetPos()).Pos
+}
+
+// chasesVIP reports whether enemy goes for VIP, it does unless the nearest soldier
+// is escort priority times closer than VIP
+func (gs *gameState) chasesVIP(e enemies.Enemy) bool {
+	if gs.vip == nil || gs.vip.IsDead() {
+		return false
+	}
+	toVIP := rl.Vector2Distance(e.GetPos(), gs.vip.Pos)
+	toSoldier := rl.Vector2Distance(e.GetPos(), findNearest(gs.soldiers, e.GetPos()).Pos)
+	return toVIP <= toSoldier*gs.balance.Escort.Priority
+}
+
+func sortedByID(data []quadtree.Data) []quadtree.Data {
+	slices.SortFunc(data, func(d1, d2 quadtree.Data) int {
+		return cmp.Compare(d1.ID, d2.ID)
+	})
+	return data
+}
+
 func (gs *gameState) cleanupDeadEnemies() {
 	aliveEnemies := gs.enemies[:0]
+	var summoned []enemies.Enemy
 	for _, e := range gs.enemies {
 		if e.IsDead() {
-			gs.score += reward(e.Reward())
-			gs.money += reward(e.Reward())
+			e.OnDeath()
+			if e.GetElite().Has(enemies.Explosive) {
+				center := rlutils.RectangleCenter(e.Boundaries())
+				gs.grenades = append(gs.grenades, grenade.FromPos(center, gs.balance.Elite.Explosion))
+			}
+			if s, ok := e.(enemies.Summoner); ok {
+				summoned = append(summoned, s.Summoned()...)
+			}
+			if _, ok := e.(*boss.Boss); ok {
+				gs.bossesKilled++
+			}
+			gs.director.Killed()
+			gs.score += gs.reward(e.Reward())
+			gs.money += gs.reward(e.Reward())
+			events.Publish(gs.events, events.EnemyKilled{
+				EnemyID: e.GetID(),
+				Pos:     e.GetPos(),
+				Reward:  gs.reward(e.Reward()),
+			})
 			continue
 		}
 		aliveEnemies = append(aliveEnemies, e)
 	}
 	gs.enemies = aliveEnemies
+	gs.addSummoned(summoned)
+}
+
+// checkObjective ends endless run with victory once its objective is done
+func (gs *gameState) checkObjective() {
+	if gs.objective == nil {
+		return
+	}
+	if gs.objective.Done(gs.objectiveProgress()) {
+		gs.endRun(true)
+	}
+}
+
+func (gs *gameState) objectiveProgress() objective.Progress {
+	return objective.Progress{
+		Time:        gs.gameTime,
+		Kills:       gs.stats.Kills,
+		Bosses:      gs.bossesKilled,
+		EnemiesLeft: gs.enemiesLeft(),
+	}
+}
+
+// retreating reports whether dawn came and enemies run away from soldiers
+func (gs *gameState) retreating() bool {
+	return gs.objective != nil && gs.objective.Retreating(gs.gameTime)
+}
+
+// enemiesComing reports whether enemies spawn: it's not a break between waves and not a retreat
+func (gs *gameState) enemiesComing() bool {
+	if gs.waves != nil && gs.waves.State() != waves.Fighting {
+		return false
+	}
+	return !gs.retreating()
 }
 
-func (gs *gameState) renderEnemies() {
+// retreatMargin is how close to the arena border enemy has to get to escape
+const retreatMargin = 2
+
+// retreatPoint is the closest point on the arena border
+func (gs *gameState) retreatPoint(pos rl.Vector2) rl.Vector2 {
+	arena := gs.boundaries.arenaBoundaries
+	left := pos.X - arena.X
+	right := arena.X + arena.Width - pos.X
+	top := pos.Y - arena.Y
+	bottom := arena.Y + arena.Height - pos.Y
+	switch min(left, right, top, bottom) {
+	case left:
+		return rl.Vector2{X: arena.X, Y: pos.Y}
+	case right:
+		return rl.Vector2{X: arena.X + arena.Width, Y: pos.Y}
+	case top:
+		return rl.Vector2{X: pos.X, Y: arena.Y}
+	default:
+		return rl.Vector2{X: pos.X, Y: arena.Y + arena.Height}
+	}
+}
+
+// removeRetreatedEnemies removes enemies that ran away at dawn, they give no reward
+func (gs *gameState) removeRetreatedEnemies() {
+	if !gs.retreating() {
+		return
+	}
+	staying := gs.enemies[:0]
 	for _, e := range gs.enemies {
-		e.Draw()
+		boundaries := e.Boundaries()
+		center := rlutils.RectangleCenter(boundaries)
+		reach := max(boundaries.Width, boundaries.Height)/2 + retreatMargin
+		if rl.Vector2Distance(center, gs.retreatPoint(center)) > reach {
+			staying = append(staying, e)
+		}
 	}
+	gs.enemies = staying
 }
 
 func (gs *gameState) cleanupDeadSoldiers() {
 	aliveSoldiers := gs.soldiers[:0]
 	for _, s := range gs.soldiers {
-		if s.Health > 0 {
+		if !s.IsDead() {
 			aliveSoldiers = append(aliveSoldiers, s)
+			continue
 		}
+		events.Publish(gs.events, events.SoldierDied{SoldierID: s.ID, Pos: s.Pos})
 	}
 	gs.soldiers = aliveSoldiers
 }
 
-func (gs *gameState) processSoldiers(flaredEnemies []enemy) {
+func damageEnemy(e enemies.Enemy, damage float32) {
+	e.TakeDamage(damage)
+	e.OnDamaged(damage)
+}
+
+func (gs *gameState) damageSoldier(s *soldier.Soldier, damage float32) {
+	s.TakeDamage(damage)
+	events.Publish(gs.events, events.SoldierDamaged{
+		SoldierID: s.ID,
+		Damage:    damage,
+		HP:        s.HP,
+	})
+}
+
+func (gs *gameState) processSoldiers(flaredEnemies []enemies.Enemy) {
+	targets := newShootingTargets(gs.enemies, flaredEnemies, gs.lineOfSight)
+
 	for _, s := range gs.soldiers {
 		s.ProgressTime(rl.GetFrameTime())
+		if burn := s.ProgressEffects(rl.GetFrameTime()); burn > 0 {
+			gs.damageSoldier(s, burn)
+		}
 
 		s.State = soldier.Standing
+		s.Target = nil
 
-		soldierBoundaries := rlutils.TextureBoundaries(s.Walking, s.Pos)
+		soldierBoundaries := s.Boundaries()
 		collissions := gs.quadtree.Query(soldierBoundaries)
 
 		for _, c := range collissions {
-			// TODO: if soldier is inside of flare - blind him
 			switch val := c.Value.(type) {
-			case enemy:
+			case enemies.Enemy:
+				if s.Stunned() {
+					break
+				}
 				s.State = soldier.Melee
-				val.TakeDamage(s.Damage)
+				damageEnemy(val, s.DealDamage())
 			case *grenade.Grenade:
-				s.Health -= val.Damage
+				val.ApplyTo(s.GetStatus())
+			case *flare.Flare:
+				// light blinds soldiers standing in it
+				if rl.CheckCollisionCircleRec(val.Pos, val.Radius, soldierBoundaries) {
+					for _, stats := range gs.balance.Effects.Flare {
+						s.Apply(effects.FromBalance(stats, val.ID))
+					}
+				}
 			}
 		}
 
 		if s.State == soldier.Standing {
-			// try to find shooting target
-			nearestEnemy := findNearest(gs.enemies, s.Pos)
-			shootFast := true
-			if nearestEnemy == nil || !s.WithinShootingRange(nearestEnemy.GetPos()) {
-				nearestEnemy = findNearest(flaredEnemies, s.Pos)
-				shootFast = false
+			nearestEnemy, shootFast := targets.find(s)
+			if nearestEnemy != nil {
+				s.Target = nearestEnemy
 			}
 			if nearestEnemy != nil &&
 				s.CanShoot(shootFast) {
@@ -1232,10 +2580,179 @@ func (gs *gameState) processSoldiers(flaredEnemies []enemy) {
 	}
 }
 
-func (gs *gameState) renderSoldiers() {
-	for _, s := range gs.soldiers {
-		s.Draw()
+// vipPlacementAttempts is how many random spots are tried for VIP and its waypoints
+const vipPlacementAttempts = 20
+
+// startEscort places VIP on a random free spot away from the arena border.
+// Only one escort can go on at a time and, like contracts, not while no enemies come.
+func (gs *gameState) startEscort() bool {
+	if gs.vip != nil || !gs.enemiesComing() {
+		return false
+	}
+	for range vipPlacementAttempts {
+		v := vip.FromPos(gs.randomInnerPos(), gs.assets.vip, gs.balance.Escort)
+		boundaries := v.Boundaries()
+		if gs.blockedByObstacles(boundaries) || len(gs.prevQuadtree.Query(boundaries)) > 0 {
+			continue
+		}
+		v.Waypoint = gs.vipWaypoint(v)
+		gs.vip = v
+		return true
+	}
+	return false
+}
+
+// randomInnerPos is random position in the arena outside of edge spawn zones
+func (gs *gameState) randomInnerPos() rl.Vector2 {
+	arena := gs.boundaries.arenaBoundaries
+	return rl.Vector2{
+		X: rlutils.RandomFloat(arena.X+spawnZoneDepth, arena.X+arena.Width-spawnZoneDepth),
+		Y: rlutils.RandomFloat(arena.Y+spawnZoneDepth, arena.Y+arena.Height-spawnZoneDepth),
+	}
+}
+
+// vipWaypoint picks where VIP wanders next, VIP walks straight, so waypoint has to be in sight.
+// VIP stays in place if nothing is found, next frame it tries again.
+func (gs *gameState) vipWaypoint(v *vip.VIP) rl.Vector2 {
+	for range vipPlacementAttempts {
+		pos := gs.randomInnerPos()
+		if gs.navGrid.LineOfSight(v.Pos, pos) {
+			return pos
+		}
 	}
+	return v.Pos
+}
+
+// processVIP moves VIP to its waypoint and ends the escort once VIP is escorted or lost.
+// Escort timer stops while no enemies come, so breaks and retreat can't be waited out.
+func (gs *gameState) processVIP() {
+	v := gs.vip
+	if v == nil {
+		return
+	}
+	coming := gs.enemiesComing()
+	if coming {
+		v.ProgressTime(rl.GetFrameTime())
+	}
+
+	if v.IsDead() {
+		penalty := min(gs.money, gs.balance.Escort.Penalty)
+		gs.money -= penalty
+		events.Publish(gs.events, events.EscortEnded{Escorted: false, Money: penalty})
+		gs.vip = nil
+		return
+	}
+	if coming && v.Escorted() {
+		payout := gs.reward(gs.balance.Escort.Payout)
+		gs.money += payout
+		gs.score += payout
+		events.Publish(gs.events, events.EscortEnded{Escorted: true, Money: payout})
+		gs.vip = nil
+		return
+	}
+
+	if move, ok := v.Move(); ok {
+		move = gs.navGrid.Slide(v.Boundaries(), move)
+		v.UpdatePosition(rl.Vector2Add(v.Pos, move))
+	} else {
+		v.Waypoint = gs.vipWaypoint(v)
+	}
+	gs.quadtree.Insert(v.ID, v.Boundaries(), v)
+}
+
+// signContract starts contract, the same contract can't go on twice at once.
+// Contracts are risky only while enemies come, so they can't be signed during breaks or retreat.
+func (gs *gameState) signContract(kind contract.Kind) bool {
+	if !gs.enemiesComing() {
+		return false
+	}
+	if !gs.contracts.Sign(kind, gs.balance.Contracts) {
+		return false
+	}
+	events.Publish(gs.events, events.ContractSigned{Name: kind.String()})
+	return true
+}
+
+// processContracts pays for contracts that ran out.
+// Timers stop while no enemies come, so breaks and retreat can't be waited out.
+func (gs *gameState) processContracts() {
+	if !gs.enemiesComing() {
+		return
+	}
+	for _, c := range gs.contracts.ProgressTime(rl.GetFrameTime()) {
+		cash := gs.reward(c.Stats.Cash)
+		score := gs.reward(c.Stats.Score)
+		gs.money += cash
+		gs.score += score
+		events.Publish(gs.events, events.ContractCompleted{Name: c.Kind.String(), Cash: cash, Score: score})
+	}
+}
+
+// shootingTargets are enemies soldiers can shoot at
+type shootingTargets struct {
+	all          []enemies.Enemy
+	flared       []enemies.Enemy
+	rangedAll    []enemies.Enemy
+	rangedFlared []enemies.Enemy
+
+	lineOfSight func(from, to rl.Vector2) bool
+}
+
+func newShootingTargets(all, flared []enemies.Enemy, lineOfSight func(from, to rl.Vector2) bool) shootingTargets {
+	return shootingTargets{
+		all:          all,
+		flared:       flared,
+		rangedAll:    filterRanged(all),
+		rangedFlared: filterRanged(flared),
+		lineOfSight:  lineOfSight,
+	}
+}
+
+// find returns target for soldier and whether soldier can shoot at it fast.
+// Enemies in shooting range are shot fast, flared enemies are shot slowly from any distance.
+// Ranged enemies go first, since they damage soldiers from afar.
+// Enemies behind obstacles can't be shot at all.
+func (st shootingTargets) find(s *soldier.Soldier) (enemies.Enemy, bool) {
+	if e := st.nearestVisible(st.rangedAll, s, true); e != nil {
+		return e, true
+	}
+	if e := st.nearestVisible(st.all, s, true); e != nil {
+		return e, true
+	}
+	if e := st.nearestVisible(st.rangedFlared, s, false); e != nil {
+		return e, false
+	}
+	return st.nearestVisible(st.flared, s, false), false
+}
+
+// nearestVisible is like findNearest, but skips enemies soldier can't see
+func (st shootingTargets) nearestVisible(items []enemies.Enemy, s *soldier.Soldier, inRange bool) enemies.Enemy {
+	var nearest enemies.Enemy
+	var minDist float32 = math.MaxFloat32
+	for _, e := range items {
+		pos := e.GetPos()
+		dist := rl.Vector2Distance(s.Pos, pos)
+		if dist >= minDist || (inRange && !s.WithinShootingRange(pos)) {
+			continue
+		}
+		// line of sight is the most expensive check, so it goes last
+		if !st.lineOfSight(s.Pos, pos) {
+			continue
+		}
+		nearest = e
+		minDist = dist
+	}
+	return nearest
+}
+
+func filterRanged(items []enemies.Enemy) []enemies.Enemy {
+	var ranged []enemies.Enemy
+	for _, e := range items {
+		if _, ok := e.(enemies.Ranged); ok {
+			ranged = append(ranged, e)
+		}
+	}
+	return ranged
 }
 
 func (gs *gameState) renderGameOver() {
@@ -1285,6 +2802,35 @@ func (gs *gameState) renderGameOver() {
 	timeY := y
 	rl.DrawText(time, timeX, timeY, fontSize, rl.White)
 
+	kills := "Kills: " + strconv.Itoa(gs.stats.Kills)
+	killsWidth := rl.MeasureText(kills, fontSize)
+	killsX := x - killsWidth/2
+	y += spacing
+	killsY := y
+	rl.DrawText(kills, killsX, killsY, fontSize, rl.White)
+
+	if escorts := gs.stats.VIPsEscorted + gs.stats.VIPsLost; escorts > 0 {
+		vips := fmt.Sprintf("VIPs escorted: %d/%d", gs.stats.VIPsEscorted, escorts)
+		vipsWidth := rl.MeasureText(vips, fontSize)
+		vipsX := x - vipsWidth/2
+		y += spacing
+		vipsY := y
+		rl.DrawText(vips, vipsX, vipsY, fontSize, rl.White)
+	}
+
+	if gs.stats.ContractsSigned > 0 {
+		completed := 0
+		for _, n := range gs.stats.ContractsCompleted {
+			completed += n
+		}
+		contracts := fmt.Sprintf("Contracts completed: %d/%d", completed, gs.stats.ContractsSigned)
+		contractsWidth := rl.MeasureText(contracts, fontSize)
+		contractsX := x - contractsWidth/2
+		y += spacing
+		contractsY := y
+		rl.DrawText(contracts, contractsX, contractsY, fontSize, rl.White)
+	}
+
 	nameInput := "Enter your name: "
 	nameInputWidth := rl.MeasureText(nameInput, fontSize)
 	nameInputX := x - nameInputWidth/2
@@ -1371,6 +2917,20 @@ func (gs *gameState) renderGameOver() {
 	rl.DrawText(backToMainMenuItemNoScore, backToMainMenuItemNoScoreX, backToMainMenuItemNoScoreY, fontSize, color)
 }
 
+func (gs *gameState) endRun(victory bool) {
+	gs.victory = victory
+	gs.gameScreen = gameScreenOver
+	for _, d := range gs.director.Decisions() {
+		rl.TraceLog(rl.LogInfo, "Director at %s: %s, %s (stress %.2f, %.2f kills/s, spawn every %.2fs)",
+			gameTimeToString(d.Time), d.Phase, d.Reason, d.Stress, d.KillRate, d.Interval)
+	}
+	events.Publish(gs.events, events.RunEnded{
+		Victory: victory,
+		Score:   gs.score * gs.scoreMultiplierForAliveSoldiers(),
+		Time:    gs.gameTime,
+	})
+}
+
 func (gs *gameState) scoreMultiplierForAliveSoldiers() int {
 	return int(math.Pow(2, float64(len(gs.soldiers))))
 }
@@ -1384,10 +2944,12 @@ func (gs *gameState) saveScore() {
 	rl.TraceLog(rl.LogInfo, "Saving score for %s: %d", gs.nameInput, score)
 
 	err := gs.db.AddHighscore(db.Highscore{
-		Name:    gs.nameInput,
-		Score:   score,
-		Time:    gs.gameTime,
-		Victory: gs.victory,
+		Name:       gs.nameInput,
+		Score:      score,
+		Time:       gs.gameTime,
+		Victory:    gs.victory,
+		Difficulty: gs.difficulty.String(),
+		Objective:  gs.objectiveName(),
 	})
 	if err != nil {
 		rl.TraceLog(rl.LogError, "Error saving highscore: %v", err)
@@ -1396,19 +2958,37 @@ func (gs *gameState) saveScore() {
 
 func (gs *gameState) reset() {
 	gs.gameTime = 0
+	gs.nextBossAt = gs.balance.Boss.Every
+	gs.bossesKilled = 0
+	gs.objective = nil
+	gs.contracts = contract.Active{}
 	gs.score = 0
 	gs.money = 0
 	gs.soldiers = nil
+	gs.vip = nil
 	gs.enemies = nil
 	gs.flares = nil
 	gs.grenades = nil
 	gs.projectiles = nil
+	gs.setObstacles(nil)
+	gs.director = director.New(gs.balance.Director)
+	gs.waves = nil
+	gs.pending = nil
+	gs.enemeSpawnedAgo = 0
 	soldierCount = 0
 	gs.nameInput = ""
 	gs.victory = false
 	gs.selectedConsumable = flares
-	gs.itemStorage.flareCount = initialFlareCount
-	gs.itemStorage.grenadeCount = initialGrenadeCount
+	gs.itemStorage.flareCount = gs.balance.InitialFlareCount
+	gs.itemStorage.grenadeCount = gs.balance.InitialGrenadeCount
+}
+
+// objectiveName is what the run had to do to be won
+func (gs *gameState) objectiveName() string {
+	if gs.objective == nil {
+		return gameModeWaves.String()
+	}
+	return gs.objective.Kind().String()
 }
 
 func (gs *gameState) renderLeaderboardScreen() {
@@ -1435,6 +3015,13 @@ func (gs *gameState) renderLeaderboardScreen() {
 		if score.Victory {
 			prefix = "Victory run"
 		}
+		if score.Difficulty != "" {
+			// records saved before difficulties were added don't have it
+			prefix += " on " + score.Difficulty
+		}
+		if score.Objective != "" {
+			prefix += " (" + score.Objective + ")"
+		}
 		score := fmt.Sprintf("%s %d points in %s", prefix, score.Score, gameTimeToString(score.Time))
 		// scoreWidth := rl.MeasureText(score, fontSize)
 		rl.DrawText(score, scoreX, y, fontSize, rl.White)
@@ -1517,14 +3104,23 @@ func buttonToString(btn int32) string {
 		return "Q"
 	case rl.KeyW:
 		return "W"
+	case rl.KeyE:
+		return "E"
+	case rl.KeyR:
+		return "R"
+	case rl.KeyT:
+		return "T"
+	case rl.KeyY:
+		return "Y"
 	default:
 		return ""
 	}
 
 }
 
-func reward(base int) int {
+// reward scales score, money and exp earned for a kill by difficulty
+func (gs *gameState) reward(base int) int {
 	// multiplier := 4 / soldierCount // Playtest
-	multiplier := 1
-	return base * multiplier
+	multiplier := gs.difficulty.stats(gs.balance.Difficulties).Rewards
+	return int(math.Round(float64(float32(base) * multiplier)))
 }
@@ -0,0 +1,1649 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/pechorka/illuminate-game-jam/internal/balance"
+	"github.com/pechorka/illuminate-game-jam/internal/consumables/flare"
+	"github.com/pechorka/illuminate-game-jam/internal/consumables/grenade"
+	"github.com/pechorka/illuminate-game-jam/internal/contract"
+	"github.com/pechorka/illuminate-game-jam/internal/director"
+	"github.com/pechorka/illuminate-game-jam/internal/ecs"
+	"github.com/pechorka/illuminate-game-jam/internal/effects"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/basic"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/boss"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/lighteater"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/spitter"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/splitter"
+	"github.com/pechorka/illuminate-game-jam/internal/enemies/swarm"
+	"github.com/pechorka/illuminate-game-jam/internal/events"
+	"github.com/pechorka/illuminate-game-jam/internal/objective"
+	"github.com/pechorka/illuminate-game-jam/internal/obstacle"
+	"github.com/pechorka/illuminate-game-jam/internal/projectile"
+	"github.com/pechorka/illuminate-game-jam/internal/soldier"
+	"github.com/pechorka/illuminate-game-jam/internal/spawnzone"
+	"github.com/pechorka/illuminate-game-jam/internal/stats"
+	"github.com/pechorka/illuminate-game-jam/internal/vip"
+	"github.com/pechorka/illuminate-game-jam/internal/waves"
+	"github.com/pechorka/illuminate-game-jam/pkg/data_structures/quadtree"
+	"github.com/pechorka/illuminate-game-jam/pkg/rlutils"
+)
+
+var testArena = rl.Rectangle{X: 0, Y: 0, Width: 1280, Height: 720}
+
+// testTexture has size, but isn't loaded to GPU, so it can't be drawn
+var testTexture = rl.Texture2D{Width: 16, Height: 16}
+
+var testBalance = func() *balance.Balance {
+	defaults, err := assets.ReadFile("assets/balance.json")
+	if err != nil {
+		panic(err)
+	}
+	b, err := balance.Parse(defaults)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}()
+
+func newTestGameState() *gameState {
+	gs := &gameState{
+		boundaries:   &gameBoundaries{arenaBoundaries: testArena},
+		prevQuadtree: quadtree.NewQuadtree(testArena, quadtreeCapacity),
+		quadtree:     quadtree.NewQuadtree(testArena, quadtreeCapacity),
+		balance:      testBalance,
+		director:     director.New(testBalance.Director),
+		events:       events.NewBus(),
+	}
+	gs.stats = stats.Subscribe(gs.events)
+	gs.setObstacles(nil)
+	return gs
+}
+
+type testShooter struct {
+	exp int
+}
+
+func (s *testShooter) EarnExp(exp int) {
+	s.exp += exp
+}
+
+func TestProcessProjectilesHighSpeed(t *testing.T) {
+	t.Run("projectile doesn't tunnel through enemy", func(t *testing.T) {
+		gs := newTestGameState()
+		e := basic.FromPos(rl.Vector2{X: 600, Y: 300}, testTexture, 0, testBalance.Enemies["basic"])
+		e.HP = 1
+		gs.enemies = append(gs.enemies, e)
+		gs.prevQuadtree.Insert(e.ID, e.Boundaries(), e)
+
+		shooter := &testShooter{}
+		p := projectile.FromPos(rl.Vector2{X: 10, Y: 308}, rl.Vector2{X: 1}, shooter)
+		p.Speed = 1200 // crosses the whole arena in one frame
+		gs.projectiles = append(gs.projectiles, p)
+
+		gs.processProjectiles()
+
+		if !e.IsDead() {
+			t.Errorf("enemy wasn't hit")
+		}
+		if len(gs.projectiles) != 0 {
+			t.Errorf("got %d projectiles, want 0", len(gs.projectiles))
+		}
+		if shooter.exp == 0 {
+			t.Errorf("shooter didn't earn exp for the kill")
+		}
+	})
+
+	t.Run("projectile hits only the first enemy on its path", func(t *testing.T) {
+		gs := newTestGameState()
+		far := basic.FromPos(rl.Vector2{X: 900, Y: 300}, testTexture, 0, testBalance.Enemies["basic"])
+		near := basic.FromPos(rl.Vector2{X: 300, Y: 300}, testTexture, 0, testBalance.Enemies["basic"])
+		for _, e := range []*basic.Enemy{far, near} {
+			gs.enemies = append(gs.enemies, e)
+			gs.prevQuadtree.Insert(e.ID, e.Boundaries(), e)
+		}
+		farHealth, nearHealth := far.HP, near.HP
+
+		p := projectile.FromPos(rl.Vector2{X: 10, Y: 308}, rl.Vector2{X: 1}, &testShooter{})
+		p.Speed = 5000
+		gs.projectiles = append(gs.projectiles, p)
+
+		gs.processProjectiles()
+
+		if near.HP != nearHealth-p.DealDamage() {
+			t.Errorf("near enemy: got health %v, want %v", near.HP, nearHealth-p.DealDamage())
+		}
+		if far.HP != farHealth {
+			t.Errorf("far enemy: got health %v, want %v", far.HP, farHealth)
+		}
+	})
+}
+
+// testEnemyBase returns base of every enemy type horde is made of
+func testEnemyBase(e enemies.Enemy) *enemies.Base {
+	switch v := e.(type) {
+	case *basic.Enemy:
+		return &v.Base
+	case *spitter.Enemy:
+		return &v.Base
+	case *splitter.Enemy:
+		return &v.Base
+	case *splitter.Child:
+		return &v.Base
+	case *swarm.Enemy:
+		return &v.Base
+	case *lighteater.Enemy:
+		return &v.Base
+	case *boss.Boss:
+		return &v.Base
+	}
+	panic(fmt.Sprintf("unexpected enemy %T", e))
+}
+
+// testHordeRoller replaces global random of constructors, so hordes with the same seed
+// get the same IDs and stats, even for enemies summoned while the test runs
+type testHordeRoller struct {
+	r      *rand.Rand
+	lastID int
+}
+
+func (h *testHordeRoller) id() int {
+	h.lastID++
+	return h.lastID
+}
+
+func (h *testHordeRoller) enemy(e enemies.Enemy) enemies.Enemy {
+	b := testEnemyBase(e)
+	b.ID = h.id()
+	b.Speed = 0.5 + h.r.Float32()*2
+	b.MaxHP = 10 + h.r.Float32()*50
+	b.HP = b.MaxHP
+	b.Amount = 1 + h.r.Float32()*5
+	if m, ok := e.(*swarm.Enemy); ok {
+		// pack took its speed from the leader before it was rolled
+		stats := testBalance.Enemies[swarm.Name]
+		m.Rebalance(stats, stats)
+	}
+	return e
+}
+
+// newTestHorde builds the same horde for the same seed. Horde mixes every enemy type,
+// so order sensitive parts of enemy update are covered: swarm heading, boss and splitter summons,
+// grenade effects, light eaters, obstacles and VIP.
+func newTestHorde(seed int64, enemyCount int) *gameState {
+	h := &testHordeRoller{r: rand.New(rand.NewSource(seed))}
+	wall := obstacle.New(obstacle.Wall, rl.Rectangle{X: 600, Y: 200, Width: 32, Height: 320})
+	wall.ID = h.id()
+	randomPos := func() rl.Vector2 {
+		for {
+			pos := rl.Vector2{X: h.r.Float32() * testArena.Width, Y: h.r.Float32() * testArena.Height}
+			if !rl.CheckCollisionPointRec(pos, wall.Bounds) {
+				return pos
+			}
+		}
+	}
+
+	gs := newTestGameState()
+	gs.setObstacles([]*obstacle.Obstacle{wall})
+	shooter := &testShooter{}
+	for range 4 {
+		s := soldier.FromPos(randomPos(), testTexture, testTexture, testBalance.Soldier, nil)
+		s.ID = h.id()
+		s.HP = 1e9 // horde is not allowed to win, test needs soldiers to move towards
+		gs.soldiers = append(gs.soldiers, s)
+		gs.quadtree.Insert(s.ID, s.Boundaries(), s)
+	}
+
+	gs.vip = vip.FromPos(randomPos(), testTexture, testBalance.Escort)
+	gs.vip.ID = h.id()
+	gs.vip.MaxHP = 1e5 // small enough for float32 to count every hit
+	gs.vip.HP = gs.vip.MaxHP
+	gs.vip.Speed = 0 // waypoints are random
+	gs.vip.Waypoint = rl.Vector2{X: -100, Y: -100}
+
+	newMinion := func(pos rl.Vector2) enemies.Enemy {
+		return h.enemy(basic.FromPos(pos, testTexture, 0, testBalance.Enemies[basic.Name]))
+	}
+	newChild := func(pos rl.Vector2) enemies.Enemy {
+		return h.enemy(splitter.ChildFromPos(pos, testTexture, 0, testBalance.Enemies[splitter.ChildName]))
+	}
+	b := boss.FromPos(randomPos(), testTexture, 0, testBalance.Boss, newMinion)
+	h.enemy(b)
+	b.MaxHP *= 5
+	// boss is one hit away from the first phase and the hit is on its way
+	b.HP = b.MaxHP*testBalance.Boss.Phases[0].HealthBelow + 1
+	gs.enemies = append(gs.enemies, b)
+	center := rlutils.RectangleCenter(b.Boundaries())
+	hit := projectile.FromPos(rl.Vector2{X: center.X - 30, Y: center.Y}, rl.Vector2{X: 1}, shooter)
+	hit.ID = h.id()
+	hit.Speed = 10
+	gs.projectiles = append(gs.projectiles, hit)
+
+	for i := 0; len(gs.enemies) < enemyCount; i++ {
+		pos := randomPos()
+		var spawned []enemies.Enemy
+		switch i % 5 {
+		case 0:
+			e := h.enemy(splitter.FromPos(pos, testTexture, 0, testBalance.Enemies[splitter.Name], newChild))
+			testEnemyBase(e).HP = 1 // dies from the first hit, so children are summoned
+			gs.enemies = append(gs.enemies, e)
+		case 1:
+			spawned = append(spawned, spitter.FromPos(pos, testTexture, 0, testBalance.Enemies[spitter.Name]))
+		case 2:
+			spawned = append(spawned, lighteater.FromPos(pos, testTexture, 0, testBalance.Enemies[lighteater.Name]))
+		case 3:
+			positions := []rl.Vector2{pos, rl.Vector2Add(pos, rl.Vector2{X: 20}), rl.Vector2Add(pos, rl.Vector2{Y: 20})}
+			for _, m := range swarm.NewPack(positions, testTexture, 0, testBalance.Enemies[swarm.Name]).Members() {
+				spawned = append(spawned, m)
+			}
+		default:
+			spawned = append(spawned, basic.FromPos(pos, testTexture, 0, testBalance.Enemies[basic.Name]))
+		}
+		for _, e := range spawned {
+			gs.enemies = append(gs.enemies, h.enemy(e))
+		}
+	}
+	for range 20 {
+		f := flare.FromPos(randomPos())
+		f.ID = h.id()
+		gs.flares = append(gs.flares, f)
+	}
+	for range 10 {
+		g := grenade.FromPos(randomPos(), testBalance.Effects.Grenade)
+		g.ID = h.id()
+		for i := range g.Effects {
+			g.Effects[i].Source = g.ID
+		}
+		gs.grenades = append(gs.grenades, g)
+	}
+	for range 200 {
+		velocity := rl.Vector2{X: h.r.Float32() - 0.5, Y: h.r.Float32() - 0.5}
+		p := projectile.FromPos(randomPos(), velocity, shooter)
+		p.ID = h.id()
+		p.Speed = 10
+		gs.projectiles = append(gs.projectiles, p)
+	}
+
+	return gs
+}
+
+// stepTestHorde is renderGame without input, rendering and spawns
+func stepTestHorde(gs *gameState, workers int) {
+	gs.prevQuadtree, gs.quadtree = gs.quadtree, gs.prevQuadtree
+	gs.quadtree.Clear()
+
+	ecs.Collide(gs.quadtree, gs.obstacles)
+	gs.processFlares()
+	gs.processGrenades()
+	gs.processProjectiles()
+	gs.processEnemiesWith(workers)
+	gs.cleanupDeadEnemies()
+	gs.cleanupDeadSoldiers()
+	for _, s := range gs.soldiers {
+		gs.quadtree.Insert(s.ID, s.Boundaries(), s)
+	}
+	gs.processVIP()
+}
+
+type hordeSnapshot struct {
+	id     int
+	pos    rl.Vector2
+	health float32
+}
+
+func snapshotHorde(gs *gameState) []hordeSnapshot {
+	var snapshot []hordeSnapshot
+	for _, s := range gs.soldiers {
+		snapshot = append(snapshot, hordeSnapshot{id: s.ID, pos: s.Pos, health: s.HP})
+	}
+	for _, e := range gs.enemies {
+		b := testEnemyBase(e)
+		snapshot = append(snapshot, hordeSnapshot{id: b.ID, pos: b.Pos, health: b.HP})
+	}
+	for _, p := range gs.projectiles {
+		snapshot = append(snapshot, hordeSnapshot{id: p.ID, pos: p.Pos})
+	}
+	for _, f := range gs.flares {
+		snapshot = append(snapshot, hordeSnapshot{id: f.ID, pos: f.Pos, health: f.Radius})
+	}
+	snapshot = append(snapshot, hordeSnapshot{id: gs.vip.ID, pos: gs.vip.Pos, health: gs.vip.HP})
+	return snapshot
+}
+
+// Run with -race to check that workers don't share state
+func TestProcessEnemiesParallelMatchesSequential(t *testing.T) {
+	for _, workers := range []int{2, 3, 8, 64} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			sequential := newTestHorde(42, 500)
+			parallel := newTestHorde(42, 500)
+
+			summoned := false
+			for frame := range 120 {
+				stepTestHorde(sequential, 1)
+				stepTestHorde(parallel, workers)
+
+				want := snapshotHorde(sequential)
+				got := snapshotHorde(parallel)
+				if !slices.Equal(got, want) {
+					t.Fatalf("frame %d: parallel update diverged from sequential", frame)
+				}
+				summoned = summoned || slices.ContainsFunc(sequential.enemies, func(e enemies.Enemy) bool {
+					_, ok := e.(*splitter.Child)
+					return ok
+				})
+			}
+
+			if sequential.stats.Kills == 0 {
+				t.Errorf("no enemy died, test doesn't check damage")
+			}
+			if !summoned {
+				t.Errorf("no splitter split, test doesn't check summons")
+			}
+			if b := sequential.enemies[0].(*boss.Boss); b.Phase() == 0 {
+				t.Errorf("boss didn't enter a phase, test doesn't check boss summons")
+			}
+			if sequential.vip.HP == sequential.vip.MaxHP {
+				t.Errorf("VIP wasn't hurt, test doesn't check VIP")
+			}
+		})
+	}
+}
+
+func BenchmarkProcessEnemies(b *testing.B) {
+	for _, enemyCount := range []int{250, 1_000, 4_000} {
+		for _, workers := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("%d enemies/%d workers", enemyCount, workers), func(b *testing.B) {
+				gs := newTestHorde(42, enemyCount)
+				for _, e := range gs.enemies {
+					testEnemyBase(e).HP = 1e9 // keep horde size stable
+				}
+				b.ResetTimer()
+				for range b.N {
+					stepTestHorde(gs, workers)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkComputeEnemyIntents measures only the parallel part of the update
+func BenchmarkComputeEnemyIntents(b *testing.B) {
+	for _, enemyCount := range []int{250, 1_000, 4_000} {
+		for _, workers := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("%d enemies/%d workers", enemyCount, workers), func(b *testing.B) {
+				gs := newTestHorde(42, enemyCount)
+				stepTestHorde(gs, workers)
+				b.ResetTimer()
+				for range b.N {
+					gs.computeEnemyIntents(workers)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkBuildSoldierField measures the part of the update that doesn't depend on horde size
+func BenchmarkBuildSoldierField(b *testing.B) {
+	gs := newTestHorde(42, 0)
+	gs.generateObstacles()
+	b.ResetTimer()
+	for range b.N {
+		gs.buildSoldierField()
+	}
+}
+
+func TestApplyBalanceKeepsHealthRatio(t *testing.T) {
+	gs := newTestGameState()
+	e := basic.FromPos(rl.Vector2{X: 100, Y: 100}, testTexture, 0, testBalance.Enemies["basic"])
+	e.HP = e.MaxHP / 4
+	gs.enemies = append(gs.enemies, e)
+	s := soldier.FromPos(rl.Vector2{X: 300, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+	s.HP = s.MaxHP / 2
+	gs.soldiers = append(gs.soldiers, s)
+
+	next := *testBalance
+	next.Enemies = map[string]balance.Enemy{
+		"basic": {
+			Speed:  balance.Range{From: 1.6, To: 2.6},
+			Health: balance.Range{From: 400, To: 800},
+			Damage: testBalance.Enemies["basic"].Damage,
+		},
+	}
+	next.Soldier.Health = 300
+	speed := e.Speed
+
+	gs.applyBalance(&next)
+
+	if got := e.HP / e.MaxHP; got != 0.25 {
+		t.Errorf("enemy: got health ratio %v, want 0.25", got)
+	}
+	if e.MaxHP < 400 || e.MaxHP > 800 {
+		t.Errorf("enemy: got max health %v, want it in new range", e.MaxHP)
+	}
+	if got, want := e.Speed, speed*2; math.Abs(float64(got-want)) > 1e-4 {
+		t.Errorf("enemy: got speed %v, want %v", got, want)
+	}
+	if got := s.HP / s.MaxHP; got != 0.5 {
+		t.Errorf("soldier: got health ratio %v, want 0.5", got)
+	}
+	if s.MaxHP != 300 {
+		t.Errorf("soldier: got max health %v, want 300", s.MaxHP)
+	}
+}
+
+type hookedEnemy struct {
+	*basic.Enemy
+
+	damaged float32
+	died    int
+	moveTo  *rl.Vector2
+}
+
+func (e *hookedEnemy) OnDamaged(damage float32) {
+	e.damaged += damage
+}
+
+func (e *hookedEnemy) OnDeath() {
+	e.died++
+}
+
+func (e *hookedEnemy) CustomMove(target rl.Vector2) (rl.Vector2, bool) {
+	if e.moveTo == nil {
+		return rl.Vector2{}, false
+	}
+	return *e.moveTo, true
+}
+
+func TestEnemyHooks(t *testing.T) {
+	gs := newTestGameState()
+	s := soldier.FromPos(rl.Vector2{X: 1000, Y: 600}, testTexture, testTexture, testBalance.Soldier, nil)
+	gs.soldiers = append(gs.soldiers, s)
+
+	moveTo := rl.Vector2{X: 50, Y: 50}
+	walker := &hookedEnemy{
+		Enemy:  basic.FromPos(rl.Vector2{X: 300, Y: 300}, testTexture, 0, testBalance.Enemies["basic"]),
+		moveTo: &moveTo,
+	}
+	victim := &hookedEnemy{
+		Enemy: basic.FromPos(rl.Vector2{X: 100, Y: 100}, testTexture, 0, testBalance.Enemies["basic"]),
+	}
+	gs.enemies = append(gs.enemies, walker, victim)
+
+	p := projectile.FromPos(rl.Vector2{X: 108, Y: 108}, rl.Vector2{X: 1}, &testShooter{})
+	p.Amount = victim.HP
+	gs.quadtree.Insert(p.ID, p.Boundaries(), p)
+
+	gs.processEnemiesWith(1)
+	gs.cleanupDeadEnemies()
+
+	if walker.Pos != moveTo {
+		t.Errorf("custom move: got pos %v, want %v", walker.Pos, moveTo)
+	}
+	if victim.damaged != p.Amount {
+		t.Errorf("on damaged: got %v damage, want %v", victim.damaged, p.Amount)
+	}
+	if victim.died != 1 {
+		t.Errorf("on death: called %d times, want 1", victim.died)
+	}
+	if walker.damaged != 0 || walker.died != 0 {
+		t.Errorf("untouched enemy got hooks called")
+	}
+	if len(gs.enemies) != 1 || gs.enemies[0] != walker {
+		t.Errorf("got %d enemies, want only walker", len(gs.enemies))
+	}
+}
+
+func TestEnemyProjectiles(t *testing.T) {
+	gs := newTestGameState()
+	s := soldier.FromPos(rl.Vector2{X: 600, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+	gs.soldiers = append(gs.soldiers, s)
+	gs.prevQuadtree.Insert(s.ID, s.Boundaries(), s)
+	// enemy between spitter and soldier shouldn't stop the shot
+	e := basic.FromPos(rl.Vector2{X: 550, Y: 300}, testTexture, 0, testBalance.Enemies["basic"])
+	gs.enemies = append(gs.enemies, e)
+	gs.prevQuadtree.Insert(e.ID, e.Boundaries(), e)
+	enemyHealth := e.HP
+
+	p := projectile.FromEnemy(rl.Vector2{X: 500, Y: 308}, rl.Vector2{X: 1}, 200, 7)
+	gs.projectiles = append(gs.projectiles, p)
+
+	gs.processProjectiles()
+
+	if got, want := s.HP, s.MaxHP-7; got != want {
+		t.Errorf("soldier: got health %v, want %v", got, want)
+	}
+	if e.HP != enemyHealth {
+		t.Errorf("enemy: got health %v, want %v", e.HP, enemyHealth)
+	}
+	if len(gs.projectiles) != 0 {
+		t.Errorf("projectile wasn't removed after hit")
+	}
+}
+
+func TestSoldiersPrioritiseRangedEnemies(t *testing.T) {
+	s := soldier.FromPos(rl.Vector2{X: 600, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+	near := basic.FromPos(rl.Vector2{X: 620, Y: 300}, testTexture, 0, testBalance.Enemies["basic"])
+	ranged := spitter.FromPos(rl.Vector2{X: 680, Y: 300}, testTexture, 0, testBalance.Enemies["spitter"])
+	farRanged := spitter.FromPos(rl.Vector2{X: 1000, Y: 300}, testTexture, 0, testBalance.Enemies["spitter"])
+	seeEverything := func(from, to rl.Vector2) bool { return true }
+
+	t.Run("ranged enemy in range goes first", func(t *testing.T) {
+		targets := newShootingTargets([]enemies.Enemy{near, ranged, farRanged}, nil, seeEverything)
+		got, fast := targets.find(s)
+		if got != ranged || !fast {
+			t.Errorf("got %v (fast %v), want spitter in range", got, fast)
+		}
+	})
+
+	t.Run("ranged enemy out of range doesn't distract", func(t *testing.T) {
+		targets := newShootingTargets([]enemies.Enemy{near, farRanged}, nil, seeEverything)
+		got, fast := targets.find(s)
+		if got != near || !fast {
+			t.Errorf("got %v (fast %v), want nearest enemy", got, fast)
+		}
+	})
+
+	t.Run("flared ranged enemy goes first", func(t *testing.T) {
+		nearFlared := basic.FromPos(rl.Vector2{X: 900, Y: 300}, testTexture, 0, testBalance.Enemies["basic"])
+		flared := []enemies.Enemy{nearFlared, farRanged}
+		targets := newShootingTargets(flared, flared, seeEverything)
+		got, fast := targets.find(s)
+		if got != farRanged || fast {
+			t.Errorf("got %v (fast %v), want flared spitter shot slowly", got, fast)
+		}
+	})
+}
+
+func newTestBossFight() *gameState {
+	gs := newTestGameState()
+	gs.assets = &gameAssets{enemy: &enemyAssets{basic: testTexture, boss: testTexture}}
+	s := soldier.FromPos(rl.Vector2{X: 1000, Y: 600}, testTexture, testTexture, testBalance.Soldier, nil)
+	gs.soldiers = append(gs.soldiers, s)
+	return gs
+}
+
+func TestBoss(t *testing.T) {
+	t.Run("summoned minions join the fight", func(t *testing.T) {
+		gs := newTestBossFight()
+		b := gs.newBoss(rl.Vector2{X: 400, Y: 300})
+		gs.enemies = append(gs.enemies, b)
+
+		damageEnemy(b, b.MaxHP*(1.01-testBalance.Boss.Phases[0].HealthBelow))
+		gs.processEnemiesWith(1)
+
+		if got, want := len(gs.enemies), 1+testBalance.Boss.Phases[0].Summon; got != want {
+			t.Errorf("got %d enemies, want %d", got, want)
+		}
+	})
+
+	t.Run("ignores flares while immune", func(t *testing.T) {
+		gs := newTestBossFight()
+		b := gs.newBoss(rl.Vector2{X: 400, Y: 300})
+		gs.enemies = append(gs.enemies, b)
+		// a bit more than needed, so rounding doesn't keep boss above the threshold
+		damageEnemy(b, b.MaxHP*(1.01-testBalance.Boss.Phases[len(testBalance.Boss.Phases)-1].HealthBelow))
+		b.Summoned()
+		f := flare.FromPos(rl.Vector2{X: 450, Y: 350})
+		gs.quadtree.Insert(f.ID, f.Boundaries(), f)
+
+		intent := gs.computeEnemyIntent(b)
+
+		if !intent.flared {
+			t.Errorf("boss in flare isn't visible to soldiers")
+		}
+		if rl.Vector2Distance(intent.newPosition, gs.soldiers[0].Pos) >= rl.Vector2Distance(b.Pos, gs.soldiers[0].Pos) {
+			t.Errorf("boss got repelled by flare")
+		}
+	})
+
+	t.Run("killing bosses wins the run", func(t *testing.T) {
+		gs := newTestBossFight()
+		gs.objective = objective.New(objective.Bosses, testBalance.Objectives)
+		for i := range testBalance.Objectives.Bosses {
+			b := gs.newBoss(rl.Vector2{X: 400, Y: 300})
+			gs.enemies = append(gs.enemies, b)
+			b.TakeDamage(b.MaxHP)
+
+			gs.cleanupDeadEnemies()
+			gs.checkObjective()
+
+			if gs.bossesKilled != i+1 {
+				t.Fatalf("got %d bosses killed, want %d", gs.bossesKilled, i+1)
+			}
+		}
+
+		if gs.gameScreen != gameScreenOver || !gs.victory {
+			t.Errorf("run didn't end with victory")
+		}
+	})
+}
+
+func newTestEnemyAssets() *gameAssets {
+	return &gameAssets{enemy: &enemyAssets{
+		basic:     testTexture,
+		fast:      testTexture,
+		tank:      testTexture,
+		spitter:   testTexture,
+		boss:      testTexture,
+		splitter:  testTexture,
+		splitling: testTexture,
+		swarm:     testTexture,
+	}}
+}
+
+func TestSplitterSpawnsChildren(t *testing.T) {
+	gs := newTestGameState()
+	gs.assets = newTestEnemyAssets()
+	gs.enemyRegistry = newEnemyRegistry(gs.assets, testBalance)
+	splitterType, _ := gs.enemyRegistry.Get(splitter.Name)
+	e := splitterType.New(rl.Vector2{X: 300, Y: 300}, 0)
+	gs.enemies = append(gs.enemies, e)
+	gs.prevQuadtree.Insert(e.GetID(), e.Boundaries(), e)
+	// soldier stands where the right child would appear
+	s := soldier.FromPos(rl.Vector2{X: 320, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+	gs.prevQuadtree.Insert(s.ID, s.Boundaries(), s)
+
+	e.TakeDamage(e.(*splitter.Enemy).MaxHP)
+	gs.cleanupDeadEnemies()
+
+	if len(gs.enemies) != 1 {
+		t.Fatalf("got %d enemies, want 1 child that had free place", len(gs.enemies))
+	}
+	child := gs.enemies[0]
+	if child.Name() != splitter.ChildName {
+		t.Errorf("got %s, want %s", child.Name(), splitter.ChildName)
+	}
+	if want := (rl.Vector2{X: 285, Y: 300}); child.GetPos() != want {
+		t.Errorf("got child pos %v, want %v", child.GetPos(), want)
+	}
+}
+
+func TestSwarmSpawnsInPacks(t *testing.T) {
+	registry := newEnemyRegistry(newTestEnemyAssets(), testBalance)
+	swarmType, _ := registry.Get(swarm.Name)
+
+	pack := swarmType.Spawn(packPositions(rl.Vector2{X: 100, Y: 100}, swarmType.Pack), 0)
+
+	if len(pack) != 8 {
+		t.Fatalf("got %d members, want 8", len(pack))
+	}
+	for i, a := range pack {
+		if a.(*swarm.Enemy).Pack() != pack[0].(*swarm.Enemy).Pack() {
+			t.Errorf("member %d is in another pack", i)
+		}
+		for _, b := range pack[i+1:] {
+			if rl.CheckCollisionRecs(a.Boundaries(), b.Boundaries()) {
+				t.Errorf("members at %v and %v overlap", a.GetPos(), b.GetPos())
+			}
+		}
+	}
+}
+
+func TestLightEater(t *testing.T) {
+	gs := newTestGameState()
+	s := soldier.FromPos(rl.Vector2{X: 100, Y: 100}, testTexture, testTexture, testBalance.Soldier, nil)
+	gs.soldiers = append(gs.soldiers, s)
+	eater := lighteater.FromPos(rl.Vector2{X: 600, Y: 300}, testTexture, 0, testBalance.Enemies[lighteater.Name])
+	eaterStart := eater.Pos
+	walker := basic.FromPos(rl.Vector2{X: 640, Y: 300}, testTexture, 0, testBalance.Enemies[basic.Name])
+	walkerStart := walker.Pos
+	gs.enemies = append(gs.enemies, eater, walker)
+	f := flare.FromPos(rl.Vector2{X: 620, Y: 330})
+	gs.flares = append(gs.flares, f)
+
+	frames := 0
+	for ; len(gs.flares) > 0; frames++ {
+		if frames > 1000 {
+			t.Fatalf("flare never went out")
+		}
+		gs.quadtree.Clear()
+		gs.processFlares()
+		gs.processEnemiesWith(1)
+		if frames == 0 {
+			if rl.Vector2Distance(eater.Pos, f.Pos) >= rl.Vector2Distance(eaterStart, f.Pos) {
+				t.Errorf("light eater didn't move to flare")
+			}
+			if rl.Vector2Distance(walker.Pos, f.Pos) <= rl.Vector2Distance(walkerStart, f.Pos) {
+				t.Errorf("basic enemy wasn't repelled by flare")
+			}
+		}
+	}
+
+	// without eater flare burns until 0.99^n drops below went out threshold
+	unattended := math.Ceil(math.Log(flare.WentOutThreshold/100.0) / math.Log(flare.DimSpd))
+	if float64(frames) >= unattended {
+		t.Errorf("flare went out in %d frames, want faster than %v frames without light eater", frames, unattended)
+	}
+
+	before := rl.Vector2Distance(eater.Pos, s.Pos)
+	gs.processEnemiesWith(1)
+	if rl.Vector2Distance(eater.Pos, s.Pos) >= before {
+		t.Errorf("light eater doesn't hunt soldiers after flare went out")
+	}
+}
+
+// maxOverlap returns the biggest part of enemy area covered by another enemy
+func maxOverlap(gs *gameState) float32 {
+	var worst float32
+	for i, a := range gs.enemies {
+		ab := a.Boundaries()
+		for _, b := range gs.enemies[i+1:] {
+			overlap := rl.GetCollisionRec(ab, b.Boundaries())
+			worst = max(worst, overlap.Width*overlap.Height/(ab.Width*ab.Height))
+		}
+	}
+	return worst
+}
+
+// Without separation both cases end with enemies almost fully covering each other
+func TestEnemiesDontPileUp(t *testing.T) {
+	t.Run("crowd spreads while walking", func(t *testing.T) {
+		gs := newTestGameState()
+		s := soldier.FromPos(rl.Vector2{X: 1200, Y: 360}, testTexture, testTexture, testBalance.Soldier, nil)
+		s.HP = 1e9
+		gs.soldiers = append(gs.soldiers, s)
+		for i := range 30 {
+			// everyone starts at almost the same pixel
+			pos := rl.Vector2{X: 100 + float32(i%3), Y: 360 + float32(i%5)}
+			e := basic.FromPos(pos, testTexture, 0, testBalance.Enemies[basic.Name])
+			e.ID = i + 1
+			e.Speed = 1
+			gs.enemies = append(gs.enemies, e)
+		}
+
+		for range 300 {
+			stepTestHorde(gs, 1)
+		}
+
+		if got, threshold := maxOverlap(gs), float32(0.3); got > threshold {
+			t.Errorf("got overlap %v, want at most %v", got, threshold)
+		}
+	})
+
+	t.Run("crowd surrounds soldier instead of stacking", func(t *testing.T) {
+		gs := newTestGameState()
+		s := soldier.FromPos(rl.Vector2{X: 640, Y: 360}, testTexture, testTexture, testBalance.Soldier, nil)
+		s.HP = 1e9
+		gs.soldiers = append(gs.soldiers, s)
+		r := rand.New(rand.NewSource(1))
+		for i := range 30 {
+			pos := rl.Vector2{X: r.Float32() * testArena.Width, Y: r.Float32() * testArena.Height}
+			e := basic.FromPos(pos, testTexture, 0, testBalance.Enemies[basic.Name])
+			e.ID = i + 1
+			e.Speed = 2
+			e.Amount = 0
+			gs.enemies = append(gs.enemies, e)
+		}
+
+		for range 1000 {
+			stepTestHorde(gs, 1)
+		}
+
+		// enemies that reach soldier push each other, but still squeeze a bit
+		if got, threshold := maxOverlap(gs), float32(0.6); got > threshold {
+			t.Errorf("got overlap %v, want at most %v", got, threshold)
+		}
+	})
+}
+
+// newTestWall returns game state with a wall that splits the arena in two,
+// with a passage at the bottom
+func newTestWall() *gameState {
+	gs := newTestGameState()
+	gs.setObstacles([]*obstacle.Obstacle{
+		obstacle.New(obstacle.Wall, rl.Rectangle{X: 640, Y: 0, Width: 32, Height: 640}),
+	})
+	return gs
+}
+
+func TestObstacles(t *testing.T) {
+	t.Run("projectile stops at obstacle", func(t *testing.T) {
+		gs := newTestWall()
+		ecs.Collide(gs.prevQuadtree, gs.obstacles)
+		e := basic.FromPos(rl.Vector2{X: 800, Y: 100}, testTexture, 0, testBalance.Enemies["basic"])
+		health := e.HP
+		gs.prevQuadtree.Insert(e.ID, e.Boundaries(), e)
+
+		p := projectile.FromPos(rl.Vector2{X: 500, Y: 108}, rl.Vector2{X: 1}, &testShooter{})
+		p.Speed = 1200
+		gs.projectiles = append(gs.projectiles, p)
+
+		gs.processProjectiles()
+
+		if e.HP != health {
+			t.Errorf("got enemy health %v, want %v", e.HP, health)
+		}
+		if len(gs.projectiles) != 0 {
+			t.Errorf("projectile wasn't removed after hitting obstacle")
+		}
+	})
+
+	t.Run("soldier doesn't shoot through obstacle", func(t *testing.T) {
+		gs := newTestWall()
+		s := soldier.FromPos(rl.Vector2{X: 600, Y: 100}, testTexture, testTexture, testBalance.Soldier, nil)
+		hidden := basic.FromPos(rl.Vector2{X: 680, Y: 100}, testTexture, 0, testBalance.Enemies["basic"])
+		visible := basic.FromPos(rl.Vector2{X: 505, Y: 100}, testTexture, 0, testBalance.Enemies["basic"])
+
+		targets := newShootingTargets([]enemies.Enemy{hidden, visible}, []enemies.Enemy{hidden}, gs.lineOfSight)
+		got, fast := targets.find(s)
+		if got != visible || !fast {
+			t.Errorf("got %v (fast %v), want visible enemy", got, fast)
+		}
+
+		targets = newShootingTargets([]enemies.Enemy{hidden}, []enemies.Enemy{hidden}, gs.lineOfSight)
+		if got, _ := targets.find(s); got != nil {
+			t.Errorf("got %v, want no target", got)
+		}
+	})
+
+	t.Run("enemy walks around obstacle", func(t *testing.T) {
+		pos := rl.Vector2{X: 500, Y: 100}
+		tests := []struct {
+			name  string
+			horde func() []enemies.Enemy
+		}{
+			{basic.Name, func() []enemies.Enemy {
+				e := basic.FromPos(pos, testTexture, 0, testBalance.Enemies[basic.Name])
+				e.Speed = 2
+				return []enemies.Enemy{e}
+			}},
+			{swarm.Name, func() []enemies.Enemy {
+				stats := testBalance.Enemies[swarm.Name]
+				stats.Speed = balance.Range{From: 2, To: 2}
+				var pack []enemies.Enemy
+				for _, m := range swarm.NewPack(packPositions(pos, 3), testTexture, 0, stats).Members() {
+					pack = append(pack, m)
+				}
+				return pack
+			}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				gs := newTestWall()
+				s := soldier.FromPos(rl.Vector2{X: 800, Y: 100}, testTexture, testTexture, testBalance.Soldier, nil)
+				s.HP = 1e9
+				gs.soldiers = append(gs.soldiers, s)
+				gs.quadtree.Insert(s.ID, s.Boundaries(), s)
+				gs.enemies = tt.horde()
+
+				for frame := 0; frame < 3000; frame++ {
+					stepTestHorde(gs, 1)
+					for _, e := range gs.enemies {
+						if gs.navGrid.Overlaps(e.Boundaries()) {
+							t.Fatalf("frame %d: enemy at %v walked into obstacle", frame, e.GetPos())
+						}
+						if rl.CheckCollisionRecs(e.Boundaries(), s.Boundaries()) {
+							return
+						}
+					}
+				}
+				t.Errorf("%d enemies didn't reach soldier", len(gs.enemies))
+			})
+		}
+	})
+
+	t.Run("enemies don't spawn on obstacles", func(t *testing.T) {
+		gs := newTestWall()
+		if gs.canSpawnAt(rl.Rectangle{X: 630, Y: 100, Width: 16, Height: 16}) {
+			t.Errorf("enemy can spawn partly inside obstacle")
+		}
+		if !gs.canSpawnAt(rl.Rectangle{X: 600, Y: 100, Width: 16, Height: 16}) {
+			t.Errorf("enemy can't spawn next to obstacle")
+		}
+	})
+}
+
+func TestSoldierField(t *testing.T) {
+	t.Run("enemy walks around flare", func(t *testing.T) {
+		gs := newTestGameState()
+		s := soldier.FromPos(rl.Vector2{X: 900, Y: 360}, testTexture, testTexture, testBalance.Soldier, nil)
+		s.HP = 1e9
+		gs.soldiers = append(gs.soldiers, s)
+		e := basic.FromPos(rl.Vector2{X: 300, Y: 360}, testTexture, 0, testBalance.Enemies[basic.Name])
+		e.Speed = 2
+		gs.enemies = append(gs.enemies, e)
+		f := flare.FromPos(rl.Vector2{X: 600, Y: 368})
+		gs.flares = append(gs.flares, f)
+
+		for frame := 0; frame < 1000; frame++ {
+			f.Radius = 80 // keep flare from dimming
+			stepTestHorde(gs, 1)
+			center := rlutils.RectangleCenter(e.Boundaries())
+			if dist := rl.Vector2Distance(center, f.Pos); dist < f.Radius/2 {
+				t.Fatalf("frame %d: enemy walked into the middle of flare, %v from its center", frame, dist)
+			}
+			if rl.CheckCollisionRecs(e.Boundaries(), s.Boundaries()) {
+				return
+			}
+		}
+		t.Errorf("enemy at %v didn't reach soldier", e.Pos)
+	})
+
+	t.Run("light eater doesn't follow it", func(t *testing.T) {
+		gs := newTestGameState()
+		s := soldier.FromPos(rl.Vector2{X: 900, Y: 360}, testTexture, testTexture, testBalance.Soldier, nil)
+		gs.soldiers = append(gs.soldiers, s)
+		gs.soldierField = gs.buildSoldierField()
+		le := lighteater.FromPos(rl.Vector2{X: 300, Y: 360}, testTexture, 0, testBalance.Enemies[lighteater.Name])
+
+		if _, ok := gs.followSoldierField(le, rlutils.RectangleCenter(le.Boundaries())); ok {
+			t.Errorf("light eater follows soldier field")
+		}
+	})
+}
+
+func TestStatusEffects(t *testing.T) {
+	t.Run("grenade burns and stuns enemies instead of hitting them", func(t *testing.T) {
+		gs := newTestGameState()
+		s := soldier.FromPos(rl.Vector2{X: 900, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+		gs.soldiers = append(gs.soldiers, s)
+		e := basic.FromPos(rl.Vector2{X: 400, Y: 300}, testTexture, 0, testBalance.Enemies[basic.Name])
+		gs.enemies = append(gs.enemies, e)
+		health := e.HP
+		g := grenade.FromPos(rl.Vector2{X: 408, Y: 308}, testBalance.Effects.Grenade)
+		gs.quadtree.Insert(g.ID, g.Boundaries(), g)
+
+		gs.processEnemiesWith(1)
+
+		if e.HP != health {
+			t.Errorf("got health %v, want %v", e.HP, health)
+		}
+		kinds := map[effects.Kind]bool{}
+		for _, effect := range e.Effects() {
+			kinds[effect.Kind] = true
+			if effect.Source != g.ID {
+				t.Errorf("got effect source %v, want grenade %v", effect.Source, g.ID)
+			}
+		}
+		if !kinds[effects.Burn] || !kinds[effects.Stun] {
+			t.Errorf("got effects %v, want burn and stun", e.Effects())
+		}
+	})
+
+	t.Run("stunned enemy doesn't move or hit", func(t *testing.T) {
+		gs := newTestGameState()
+		s := soldier.FromPos(rl.Vector2{X: 400, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+		gs.soldiers = append(gs.soldiers, s)
+		gs.prevQuadtree.Insert(s.ID, s.Boundaries(), s)
+		e := basic.FromPos(rl.Vector2{X: 405, Y: 300}, testTexture, 0, testBalance.Enemies[basic.Name])
+		e.Apply(effects.Effect{Kind: effects.Stun, Source: 1, Left: 1})
+		gs.enemies = append(gs.enemies, e)
+		pos := e.Pos
+
+		gs.processEnemiesWith(1)
+
+		if e.Pos != pos {
+			t.Errorf("got pos %v, want %v", e.Pos, pos)
+		}
+		if s.HP != s.MaxHP {
+			t.Errorf("got soldier health %v, want %v", s.HP, s.MaxHP)
+		}
+	})
+
+	t.Run("slowed enemy walks slower", func(t *testing.T) {
+		gs := newTestGameState()
+		s := soldier.FromPos(rl.Vector2{X: 900, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+		gs.soldiers = append(gs.soldiers, s)
+		gs.soldierField = gs.buildSoldierField()
+		e := basic.FromPos(rl.Vector2{X: 400, Y: 300}, testTexture, 0, testBalance.Enemies[basic.Name])
+
+		normal := rl.Vector2Distance(gs.computeEnemyIntent(e).newPosition, e.Pos)
+		e.Apply(effects.Effect{Kind: effects.Slow, Source: 1, Strength: 0.5, Left: 1})
+		slowed := rl.Vector2Distance(gs.computeEnemyIntent(e).newPosition, e.Pos)
+
+		if normal == 0 {
+			t.Fatal("enemy doesn't move")
+		}
+		if math.Abs(float64(slowed-normal/2)) > 1e-4 {
+			t.Errorf("got step %v, want half of %v", slowed, normal)
+		}
+	})
+
+	t.Run("flare blinds soldier", func(t *testing.T) {
+		gs := newTestGameState()
+		s := soldier.FromPos(rl.Vector2{X: 400, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+		gs.soldiers = append(gs.soldiers, s)
+		f := flare.FromPos(rl.Vector2{X: 410, Y: 310})
+		gs.quadtree.Insert(f.ID, f.Boundaries(), f)
+
+		gs.processSoldiers(nil)
+
+		want := s.ShootingRange * (1 - testBalance.Effects.Flare[0].Strength)
+		if got := s.Range(); got != want {
+			t.Errorf("got range %v, want %v", got, want)
+		}
+	})
+}
+
+func TestElites(t *testing.T) {
+	t.Run("spawned enemies roll affixes after unlock", func(t *testing.T) {
+		gs := newTestGameState()
+		gs.assets = newTestEnemyAssets()
+		b := *testBalance
+		b.Elite.Chance = 1
+		gs.balance = &b
+		gs.enemyRegistry = newEnemyRegistry(gs.assets, gs.balance)
+		gs.enemeSpawnedAgo = gs.balance.InitialSpawnRate
+		gs.spawnRate = gs.balance.InitialSpawnRate
+		gs.gameTime = b.Elite.UnlockAt
+
+		gs.spawnEnemies()
+		gs.progressTelegraphs(spawnzone.TelegraphDuration)
+
+		if len(gs.enemies) == 0 {
+			t.Fatal("nothing spawned")
+		}
+		for _, e := range gs.enemies {
+			if _, ok := e.(*boss.Boss); ok {
+				continue
+			}
+			if !e.GetElite().IsElite() {
+				t.Errorf("%s isn't elite", e.Name())
+			}
+		}
+	})
+
+	t.Run("vampiric elite heals when it hits soldier", func(t *testing.T) {
+		gs := newTestGameState()
+		s := soldier.FromPos(rl.Vector2{X: 400, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+		gs.soldiers = append(gs.soldiers, s)
+		gs.prevQuadtree.Insert(s.ID, s.Boundaries(), s)
+		e := basic.FromPos(rl.Vector2{X: 405, Y: 300}, testTexture, 0, testBalance.Enemies[basic.Name])
+		e.MakeElite([]enemies.Affix{enemies.Vampiric}, 0, testBalance.Elite)
+		e.HP = 1
+		gs.enemies = append(gs.enemies, e)
+
+		gs.processEnemiesWith(1)
+
+		if s.HP == s.MaxHP {
+			t.Fatal("soldier wasn't hit")
+		}
+		if want := 1 + e.DealDamage()*testBalance.Elite.Vampiric; e.HP != want {
+			t.Errorf("got health %v, want %v", e.HP, want)
+		}
+	})
+
+	t.Run("explosive elite leaves blast on death", func(t *testing.T) {
+		gs := newTestGameState()
+		e := basic.FromPos(rl.Vector2{X: 400, Y: 300}, testTexture, 0, testBalance.Enemies[basic.Name])
+		e.MakeElite([]enemies.Affix{enemies.Explosive}, 0, testBalance.Elite)
+		gs.enemies = append(gs.enemies, e)
+
+		e.TakeDamage(e.MaxHP)
+		gs.cleanupDeadEnemies()
+
+		if len(gs.grenades) != 1 {
+			t.Fatalf("got %d blasts, want 1", len(gs.grenades))
+		}
+		if got, want := gs.grenades[0].Pos, rlutils.RectangleCenter(e.Boundaries()); got != want {
+			t.Errorf("got blast at %v, want %v", got, want)
+		}
+		if len(gs.grenades[0].Effects) != len(testBalance.Elite.Explosion) {
+			t.Errorf("got effects %v, want %v", gs.grenades[0].Effects, testBalance.Elite.Explosion)
+		}
+	})
+}
+
+func TestDirectorSnapshot(t *testing.T) {
+	t.Run("lost soldiers count as empty", func(t *testing.T) {
+		gs := newTestGameState()
+		s := soldier.FromPos(rl.Vector2{X: 400, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+		s.HP = s.MaxHP / 2
+		gs.soldiers = append(gs.soldiers, s)
+		soldierCount = 2
+		defer func() { soldierCount = 0 }()
+
+		if got := gs.soldierHealth(); got != 0.25 {
+			t.Errorf("got health %v, want 0.25", got)
+		}
+	})
+
+	t.Run("flare coverage", func(t *testing.T) {
+		gs := newTestGameState()
+		if got := gs.flareCoverage(); got != 0 {
+			t.Errorf("got coverage %v without flares, want 0", got)
+		}
+
+		gs.flares = append(gs.flares, flare.FromPos(rl.Vector2{X: 640, Y: 360}))
+		got := gs.flareCoverage()
+		if got <= 0 || got >= 0.5 {
+			t.Errorf("got coverage %v of one flare, want small part of arena", got)
+		}
+	})
+
+	t.Run("kills are counted towards kill rate", func(t *testing.T) {
+		gs := newTestGameState()
+		e := basic.FromPos(rl.Vector2{X: 400, Y: 300}, testTexture, 0, testBalance.Enemies[basic.Name])
+		gs.enemies = append(gs.enemies, e)
+		e.TakeDamage(e.MaxHP)
+
+		gs.cleanupDeadEnemies()
+		gs.director.Update(0, director.Snapshot{BaseInterval: 1})
+
+		if gs.director.KillRate() == 0 {
+			t.Errorf("kill wasn't counted")
+		}
+	})
+}
+
+func TestWaveMode(t *testing.T) {
+	newTestWaves := func(groups ...waves.Group) *gameState {
+		gs := newTestGameState()
+		gs.assets = newTestEnemyAssets()
+		gs.enemyRegistry = newEnemyRegistry(gs.assets, testBalance)
+		gs.waves = waves.NewScript(&waves.File{Waves: []waves.Wave{{Groups: groups}}})
+		return gs
+	}
+
+	t.Run("default waves are valid", func(t *testing.T) {
+		if _, err := loadWaves("missing-waves.json", testBalance); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("enemies come from their zones", func(t *testing.T) {
+		gs := newTestWaves(
+			waves.Group{Enemy: basic.Name, Count: 1, Zones: map[string]float32{"left": 1}},
+			waves.Group{Enemy: swarm.Name, Count: 1, Zones: map[string]float32{"bottom": 1}},
+		)
+
+		gs.spawnEnemies()
+		gs.progressTelegraphs(spawnzone.TelegraphDuration)
+
+		if len(gs.enemies) != 1+testBalance.Enemies[swarm.Name].Spawn.Pack {
+			t.Fatalf("got %d enemies, want basic and swarm pack", len(gs.enemies))
+		}
+		if x := gs.enemies[0].GetPos().X; x >= testArena.X+spawnZoneDepth {
+			t.Errorf("got basic at x %v, want it in left zone", x)
+		}
+		for _, e := range gs.enemies[1:] {
+			if e.Boundaries().Y < testArena.Height-spawnZoneDepth-packExtent(len(gs.enemies)-1) {
+				t.Errorf("got swarm at %v, want it near bottom edge", e.GetPos())
+			}
+		}
+	})
+
+	t.Run("enemies left counts every pack member", func(t *testing.T) {
+		gs := newTestWaves(
+			waves.Group{Enemy: basic.Name, Count: 1},
+			waves.Group{Enemy: swarm.Name, Count: 2},
+			waves.Group{Enemy: boss.Name, Count: 1},
+		)
+		want := 1 + 2*testBalance.Enemies[swarm.Name].Spawn.Pack + 1
+		if got := gs.waves.Remaining(gs.enemiesLeft(), gs.packSize); got != want {
+			t.Errorf("got %d enemies left, want %d", got, want)
+		}
+	})
+
+	t.Run("clearing the last wave wins", func(t *testing.T) {
+		gs := newTestWaves(waves.Group{Enemy: basic.Name, Count: 1})
+		gs.spawnEnemies()
+		gs.spawnEnemies()
+		if gs.victory {
+			t.Fatal("won while enemy was telegraphed")
+		}
+		gs.progressTelegraphs(spawnzone.TelegraphDuration)
+		gs.spawnEnemies()
+		if gs.victory {
+			t.Fatal("won before wave was cleared")
+		}
+
+		gs.enemies[0].TakeDamage(1e9)
+		gs.cleanupDeadEnemies()
+		gs.spawnEnemies()
+
+		if !gs.victory || gs.gameScreen != gameScreenOver {
+			t.Errorf("run didn't end with victory")
+		}
+	})
+}
+
+func TestSpawnZones(t *testing.T) {
+	newTestSpawner := func() *gameState {
+		gs := newTestGameState()
+		gs.assets = newTestEnemyAssets()
+		gs.enemyRegistry = newEnemyRegistry(gs.assets, testBalance)
+		gs.spawnRate = testBalance.InitialSpawnRate
+		gs.enemeSpawnedAgo = testBalance.InitialSpawnRate
+		gs.nextBossAt = testBalance.Boss.Every
+		return gs
+	}
+
+	t.Run("enemies spawn after telegraph", func(t *testing.T) {
+		gs := newTestSpawner()
+
+		gs.spawnEnemies()
+		if len(gs.enemies) != 0 || len(gs.pending) != 1 {
+			t.Fatalf("got %d enemies and %d telegraphs, want only telegraph", len(gs.enemies), len(gs.pending))
+		}
+		gs.progressTelegraphs(spawnzone.TelegraphDuration / 2)
+		if len(gs.enemies) != 0 {
+			t.Fatalf("enemies spawned before telegraph ran out")
+		}
+		gs.progressTelegraphs(spawnzone.TelegraphDuration / 2)
+		if len(gs.enemies) == 0 || len(gs.pending) != 0 {
+			t.Errorf("got %d enemies and %d telegraphs, want telegraph to spawn", len(gs.enemies), len(gs.pending))
+		}
+	})
+
+	t.Run("flare over telegraph holds spawn", func(t *testing.T) {
+		gs := newTestSpawner()
+		gs.spawnEnemies()
+
+		f := flare.FromPos(rlutils.RectangleCenter(gs.pending[0].telegraph.Area))
+		f.Radius = gs.pending[0].telegraph.Area.Width
+		gs.flares = append(gs.flares, f)
+		gs.progressTelegraphs(spawnzone.TelegraphDuration)
+		if len(gs.enemies) != 0 {
+			t.Fatalf("enemies spawned in flare light")
+		}
+
+		gs.flares = nil
+		gs.progressTelegraphs(0)
+		if len(gs.enemies) == 0 {
+			t.Errorf("enemies didn't spawn once flare was gone")
+		}
+	})
+
+	t.Run("soldier near telegraph holds spawn", func(t *testing.T) {
+		gs := newTestSpawner()
+		e := basic.FromPos(rl.Vector2{X: 100, Y: 300}, testTexture, 0, testBalance.Enemies[basic.Name])
+		gs.telegraph([]enemies.Enemy{e})
+
+		s := soldier.FromPos(rl.Vector2{X: 150, Y: 300}, testTexture, testTexture, testBalance.Soldier, nil)
+		gs.soldiers = append(gs.soldiers, s)
+		gs.progressTelegraphs(spawnzone.TelegraphDuration)
+		if len(gs.enemies) != 0 {
+			t.Fatalf("enemy spawned in soldier's shooting range")
+		}
+
+		gs.soldiers = nil
+		gs.progressTelegraphs(0)
+		if len(gs.enemies) != 1 {
+			t.Errorf("enemy didn't spawn once soldier was gone")
+		}
+	})
+
+	t.Run("director weights zones", func(t *testing.T) {
+		gs := newTestSpawner()
+		nest := rl.Rectangle{X: 400, Y: 300, Width: 90, Height: 90}
+		gs.setNests([]rl.Rectangle{nest})
+		b := *testBalance
+		onlyNests := balance.ZoneWeights{Nest: 1}
+		b.Director.Zones = balance.Zones{BuildUp: onlyNests, Peak: onlyNests, Relax: onlyNests}
+		gs.balance = &b
+		gs.director = director.New(b.Director)
+
+		for range 5 {
+			gs.enemeSpawnedAgo = b.InitialSpawnRate
+			gs.pending = nil
+			gs.spawnEnemies()
+
+			if len(gs.pending) != 1 {
+				t.Fatalf("got %d telegraphs, want 1", len(gs.pending))
+			}
+			if area := gs.pending[0].telegraph.Area; !rl.CheckCollisionRecs(area, nest) {
+				t.Errorf("got telegraph at %v, want it in nest %v", area, nest)
+			}
+		}
+	})
+}
+
+func TestDifficulty(t *testing.T) {
+	newTestDifficulty := func() *gameState {
+		gs := newTestGameState()
+		gs.assets = newTestEnemyAssets()
+		gs.itemStorage = &itemStorage{}
+		b := *testBalance
+		b.Difficulties.Hard = balance.Difficulty{Stats: 2, SpawnRate: 0.5, Consumables: 0.5, Prices: 2, Rewards: 1.5}
+		gs.loadedBalance = &b
+		return gs
+	}
+
+	t.Run("preset scales balance", func(t *testing.T) {
+		gs := newTestDifficulty()
+
+		gs.setDifficulty(difficultyHard)
+
+		if got, want := gs.spawnRate, testBalance.InitialSpawnRate/2; got != want {
+			t.Errorf("got spawn rate %v, want %v", got, want)
+		}
+		if got, want := gs.itemStorage.flareCount, testBalance.InitialFlareCount/2; got != want {
+			t.Errorf("got %d flares, want %d", got, want)
+		}
+		if got, want := gs.balance.Shop["flare"].Price, testBalance.Shop["flare"].Price*2; got != want {
+			t.Errorf("got flare price %d, want %d", got, want)
+		}
+		if got, want := gs.reward(10), 15; got != want {
+			t.Errorf("got reward %d, want %d", got, want)
+		}
+	})
+
+	t.Run("switching back restores balance", func(t *testing.T) {
+		gs := newTestDifficulty()
+
+		gs.setDifficulty(difficultyHard)
+		gs.setDifficulty(difficultyNormal)
+
+		if got, want := gs.balance.Enemies[basic.Name].Health, testBalance.Enemies[basic.Name].Health; got != want {
+			t.Errorf("got basic health %v, want %v", got, want)
+		}
+		if got := gs.reward(10); got != 10 {
+			t.Errorf("got reward %d, want 10", got)
+		}
+	})
+}
+
+func TestObjectives(t *testing.T) {
+	t.Run("kills win the run", func(t *testing.T) {
+		gs := newTestGameState()
+		gs.objective = objective.New(objective.Kills, testBalance.Objectives)
+		gs.stats.Kills = testBalance.Objectives.Kills - 1
+		gs.checkObjective()
+		if gs.victory {
+			t.Fatal("won before enough kills")
+		}
+
+		e := basic.FromPos(rl.Vector2{X: 400, Y: 300}, testTexture, 0, testBalance.Enemies[basic.Name])
+		gs.enemies = append(gs.enemies, e)
+		e.TakeDamage(e.MaxHP)
+		gs.cleanupDeadEnemies()
+		gs.checkObjective()
+
+		if !gs.victory || gs.gameScreen != gameScreenOver {
+			t.Errorf("run didn't end with victory")
+		}
+	})
+
+	t.Run("enemies retreat at dawn", func(t *testing.T) {
+		gs := newTestGameState()
+		gs.assets = newTestEnemyAssets()
+		gs.enemyRegistry = newEnemyRegistry(gs.assets, testBalance)
+		gs.objective = objective.New(objective.Dawn, testBalance.Objectives)
+		s := soldier.FromPos(rl.Vector2{X: 640, Y: 360}, testTexture, testTexture, testBalance.Soldier, nil)
+		gs.soldiers = append(gs.soldiers, s)
+		e := basic.FromPos(rl.Vector2{X: testArena.X + 30, Y: 360}, testTexture, 0, testBalance.Enemies[basic.Name])
+		gs.enemies = append(gs.enemies, e)
+		gs.gameTime = testBalance.Objectives.Dawn
+
+		intent := gs.computeEnemyIntent(e)
+		if intent.newPosition.X >= e.Pos.X {
+			t.Errorf("got enemy moving from %v to %v, want it to run to the left edge", e.Pos, intent.newPosition)
+		}
+
+		gs.enemeSpawnedAgo = testBalance.InitialSpawnRate
+		gs.spawnEnemies()
+		if len(gs.pending) != 0 {
+			t.Errorf("enemies spawn at dawn")
+		}
+
+		e.Pos.X = testArena.X
+		gs.removeRetreatedEnemies()
+		gs.checkObjective()
+		if len(gs.enemies) != 0 || !gs.victory {
+			t.Errorf("got %d enemies and victory %v, want every enemy gone and run won", len(gs.enemies), gs.victory)
+		}
+	})
+
+	t.Run("crowded arena doesn't win the run", func(t *testing.T) {
+		gs := newTestGameState()
+		gs.assets = newTestEnemyAssets()
+		gs.enemyRegistry = newEnemyRegistry(gs.assets, testBalance)
+		// soldier sees the whole arena, so there is nowhere to spawn
+		b := *testBalance
+		b.Soldier.ShootingRange = 1e6
+		s := soldier.FromPos(rl.Vector2{X: 640, Y: 360}, testTexture, testTexture, b.Soldier, nil)
+		gs.soldiers = append(gs.soldiers, s)
+
+		if pack := gs.spawnEnemy(); pack != nil {
+			t.Fatalf("spawned %d enemies in sight of soldier", len(pack))
+		}
+		if gs.victory {
+			t.Errorf("run ended with victory")
+		}
+	})
+}
+
+func TestEscort(t *testing.T) {
+	newTestEscort := func() *gameState {
+		gs := newTestGameState()
+		gs.assets = newTestEnemyAssets()
+		gs.assets.vip = testTexture
+		s := soldier.FromPos(rl.Vector2{X: 640, Y: 360}, testTexture, testTexture, testBalance.Soldier, nil)
+		gs.soldiers = append(gs.soldiers, s)
+		if !gs.startEscort() {
+			t.Fatal("escort didn't start")
+		}
+		return gs
+	}
+
+	t.Run("one escort at a time", func(t *testing.T) {
+		gs := newTestEscort()
+		if gs.startEscort() {
+			t.Errorf("second escort started while VIP is alive")
+		}
+	})
+
+	t.Run("escorted VIP pays out", func(t *testing.T) {
+		gs := newTestEscort()
+		gs.processVIP()
+		if gs.vip == nil {
+			t.Fatal("escort ended right away")
+		}
+
+		gs.vip.Left = 0
+		gs.processVIP()
+
+		if gs.vip != nil {
+			t.Errorf("escort didn't end")
+		}
+		if want := testBalance.Escort.Payout; gs.money != want || gs.score != want {
+			t.Errorf("got %d money and %d score, want %d", gs.money, gs.score, want)
+		}
+	})
+
+	t.Run("lost VIP costs money", func(t *testing.T) {
+		gs := newTestEscort()
+		gs.money = testBalance.Escort.Penalty + 10
+		gs.vip.TakeDamage(gs.vip.MaxHP)
+		gs.processVIP()
+
+		if gs.vip != nil || gs.money != 10 {
+			t.Errorf("got VIP %v and %d money, want escort over and 10 money left", gs.vip, gs.money)
+		}
+
+		gs.startEscort()
+		gs.vip.TakeDamage(gs.vip.MaxHP)
+		gs.processVIP()
+		if gs.money != 0 {
+			t.Errorf("got %d money, want penalty to take what's left", gs.money)
+		}
+	})
+
+	t.Run("escort stops during wave break", func(t *testing.T) {
+		gs := newTestEscort()
+		gs.waves = waves.NewScript(&waves.File{Waves: []waves.Wave{{Break: 30}, {}}})
+		gs.waves.Update(0, 0)
+		if gs.waves.State() != waves.Break {
+			t.Fatalf("got wave state %v, want break", gs.waves.State())
+		}
+
+		gs.vip.Left = 0
+		gs.processVIP()
+		if gs.vip == nil || gs.money != 0 || gs.score != 0 {
+			t.Errorf("got %d money and %d score, want escort to wait for the next wave", gs.money, gs.score)
+		}
+
+		gs.waves.SkipBreak()
+		gs.processVIP()
+		if gs.vip != nil || gs.money != testBalance.Escort.Payout {
+			t.Errorf("got %d money, want escort paid once wave started", gs.money)
+		}
+	})
+
+	t.Run("no escort during wave break", func(t *testing.T) {
+		gs := newTestGameState()
+		gs.waves = waves.NewScript(&waves.File{Waves: []waves.Wave{{Break: 30}, {}}})
+		gs.waves.Update(0, 0)
+		if gs.startEscort() {
+			t.Errorf("escort started during break")
+		}
+	})
+
+	t.Run("enemies go for VIP first", func(t *testing.T) {
+		gs := newTestEscort()
+		gs.vip.UpdatePosition(rl.Vector2{X: 300, Y: 360})
+
+		near := basic.FromPos(rl.Vector2{X: 200, Y: 360}, testTexture, 0, testBalance.Enemies[basic.Name])
+		if got := gs.enemyTarget(near); got != gs.vip.Pos {
+			t.Errorf("got target %v, want VIP at %v", got, gs.vip.Pos)
+		}
+		// soldier is more than priority times closer
+		far := basic.FromPos(rl.Vector2{X: 900, Y: 360}, testTexture, 0, testBalance.Enemies[basic.Name])
+		if got := gs.enemyTarget(far); got != gs.soldiers[0].Pos {
+			t.Errorf("got target %v, want soldier at %v", got, gs.soldiers[0].Pos)
+		}
+	})
+
+	t.Run("enemies hurt VIP", func(t *testing.T) {
+		gs := newTestEscort()
+		e := basic.FromPos(gs.vip.Pos, testTexture, 0, testBalance.Enemies[basic.Name])
+		gs.enemies = append(gs.enemies, e)
+		gs.prevQuadtree.Insert(gs.vip.ID, gs.vip.Boundaries(), gs.vip)
+
+		gs.processEnemiesWith(1)
+
+		if gs.vip.HP >= gs.vip.MaxHP {
+			t.Errorf("VIP wasn't hurt")
+		}
+	})
+}
+
+func TestContracts(t *testing.T) {
+	t.Run("completed contract pays", func(t *testing.T) {
+		gs := newTestGameState()
+		if !gs.signContract(contract.Blackout) {
+			t.Fatal("contract wasn't signed")
+		}
+		if gs.signContract(contract.Blackout) {
+			t.Errorf("signed the same contract twice")
+		}
+
+		gs.processContracts()
+		if len(gs.contracts.List()) != 1 || gs.money != 0 {
+			t.Fatalf("contract ended right away")
+		}
+
+		gs.contracts.List()[0].Left = 0
+		gs.processContracts()
+
+		stats := testBalance.Contracts.Blackout
+		if gs.money != stats.Cash || gs.score != stats.Score {
+			t.Errorf("got %d money and %d score, want %d and %d", gs.money, gs.score, stats.Cash, stats.Score)
+		}
+		if gs.contracts.Has(contract.Blackout) {
+			t.Errorf("completed contract is still active")
+		}
+	})
+
+	t.Run("contracts stop during wave break", func(t *testing.T) {
+		gs := newTestGameState()
+		gs.waves = waves.NewScript(&waves.File{Waves: []waves.Wave{{Break: 30}, {}}})
+		if !gs.signContract(contract.Blackout) {
+			t.Fatal("contract wasn't signed during wave")
+		}
+
+		gs.waves.Update(0, 0)
+		if gs.waves.State() != waves.Break {
+			t.Fatalf("got wave state %v, want break", gs.waves.State())
+		}
+		if gs.signContract(contract.Elites) {
+			t.Errorf("contract was signed during break")
+		}
+		gs.contracts.List()[0].Left = 0
+		gs.processContracts()
+		if gs.money != 0 || gs.score != 0 || !gs.contracts.Has(contract.Blackout) {
+			t.Errorf("got %d money and %d score, want contract to wait for the next wave", gs.money, gs.score)
+		}
+
+		gs.waves.SkipBreak()
+		gs.processContracts()
+		if gs.money != testBalance.Contracts.Blackout.Cash {
+			t.Errorf("got %d money, want contract paid once wave started", gs.money)
+		}
+	})
+
+	t.Run("elite wave makes every enemy elite", func(t *testing.T) {
+		gs := newTestGameState()
+		gs.signContract(contract.Elites)
+
+		e := basic.FromPos(rl.Vector2{X: 400, Y: 300}, testTexture, 0, testBalance.Enemies[basic.Name])
+		gs.addSpawned([]enemies.Enemy{e})
+
+		if !e.GetElite().IsElite() {
+			t.Errorf("enemy spawned during elite wave isn't elite")
+		}
+	})
+
+	t.Run("no frenzy in wave mode", func(t *testing.T) {
+		gs := newTestGameState()
+		gs.waves = waves.NewScript(&waves.File{Waves: []waves.Wave{{}}})
+		for _, item := range gs.contractItems() {
+			if item.name == contract.Frenzy.String() {
+				t.Errorf("frenzy is offered in wave mode")
+			}
+		}
+	})
+}
+
+func TestHowToPlayFitsWindow(t *testing.T) {
+	// lines start 360px left of the middle of 1280px window, 72 characters of 20px font take around 800px
+	const maxLineLength = 72
+	// text starts at 70px and the back button needs room below it
+	const maxLines = (720 - 70 - 60) / 30
+	for i, page := range howToPlayPages {
+		if len(page) > maxLines {
+			t.Errorf("page %d: got %d lines, want at most %d", i+1, len(page), maxLines)
+		}
+		for _, line := range page {
+			if len(line) > maxLineLength {
+				t.Errorf("page %d: %q is longer than %d characters", i+1, line, maxLineLength)
+			}
+		}
+	}
+}
+
+func TestReloadMissingTextureKeepsOld(t *testing.T) {
+	gs := newTestGameState()
+	texture := rl.Texture2D{ID: 7, Width: 16, Height: 16}
+	s := soldier.FromPos(rl.Vector2{X: 640, Y: 360}, texture, texture, testBalance.Soldier, nil)
+	gs.soldiers = append(gs.soldiers, s)
+
+	gs.reloadTexture("assets/missing.png", &texture)
+
+	if texture.ID != 7 || s.Texture.ID != 7 {
+		t.Errorf("got texture %d and soldier texture %d, want both kept", texture.ID, s.Texture.ID)
+	}
+}
+
+func TestReplaceTexture(t *testing.T) {
+	gs := newTestGameState()
+	old := rl.Texture2D{ID: 7, Width: 16, Height: 16}
+	next := rl.Texture2D{ID: 8, Width: 16, Height: 16}
+	alive := basic.FromPos(rl.Vector2{X: 400, Y: 300}, old, 0, testBalance.Enemies[basic.Name])
+	gs.enemies = append(gs.enemies, alive)
+	pending := basic.FromPos(rl.Vector2{X: 10, Y: 300}, old, 0, testBalance.Enemies[basic.Name])
+	gs.telegraph([]enemies.Enemy{pending})
+
+	gs.replaceTexture(old, next)
+
+	if alive.Texture.ID != next.ID || pending.Texture.ID != next.ID {
+		t.Errorf("got alive enemy texture %d and pending enemy texture %d, want both %d", alive.Texture.ID, pending.Texture.ID, next.ID)
+	}
+}
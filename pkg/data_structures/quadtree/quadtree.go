@@ -1,6 +1,12 @@
 package quadtree
 
-import rl "github.com/gen2brain/raylib-go/raylib"
+import (
+	"cmp"
+	"slices"
+	"sync/atomic"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
 
 type Data struct {
 	ID         int
@@ -16,6 +22,9 @@ type Quadtree struct {
 	data map[int]Data
 
 	Regions []*Quadtree
+
+	// queries can be made from multiple goroutines while nobody inserts
+	queries atomic.Int64
 }
 
 func NewQuadtree(bounds rl.Rectangle, capacity int) *Quadtree {
@@ -69,6 +78,11 @@ func (q *Quadtree) Insert(id int, boundaries rl.Rectangle, data any) bool {
 }
 
 func (q *Quadtree) Query(rect rl.Rectangle) []Data {
+	q.queries.Add(1)
+	return q.query(rect)
+}
+
+func (q *Quadtree) query(rect rl.Rectangle) []Data {
 	if !rl.CheckCollisionRecs(q.Bounds, rect) {
 		return nil
 	}
@@ -81,12 +95,116 @@ func (q *Quadtree) Query(rect rl.Rectangle) []Data {
 	}
 
 	for _, region := range q.Regions {
-		collidedData = append(collidedData, region.Query(rect)...)
+		collidedData = append(collidedData, region.query(rect)...)
 	}
 
 	return collidedData
 }
 
+// Hit is a result of a sweep query.
+// T is the fraction of the path (0 - start, 1 - end) at which the swept shape
+// first touches the data boundaries.
+type Hit struct {
+	Data
+	T float32
+}
+
+// QuerySegment returns data intersected by the segment from start to end,
+// ordered from the nearest to start to the farthest.
+func (q *Quadtree) QuerySegment(start, end rl.Vector2) []Hit {
+	return q.QuerySweptCircle(start, end, 0)
+}
+
+// QuerySweptCircle returns data touched by a circle of given radius
+// moving from start to end, ordered from the nearest to start to the farthest.
+// Boundaries are inflated by radius, so corners are treated as square.
+func (q *Quadtree) QuerySweptCircle(start, end rl.Vector2, radius float32) []Hit {
+	q.queries.Add(1)
+	hits := q.querySwept(start, end, radius)
+	slices.SortFunc(hits, func(h1, h2 Hit) int {
+		return cmp.Or(cmp.Compare(h1.T, h2.T), cmp.Compare(h1.ID, h2.ID))
+	})
+	return hits
+}
+
+func (q *Quadtree) querySwept(start, end rl.Vector2, radius float32) []Hit {
+	if _, ok := sweepRect(start, end, q.Bounds, radius); !ok {
+		return nil
+	}
+
+	var hits []Hit
+	for _, data := range q.data {
+		if t, ok := sweepRect(start, end, data.Boundaries, radius); ok {
+			hits = append(hits, Hit{Data: data, T: t})
+		}
+	}
+
+	for _, region := range q.Regions {
+		hits = append(hits, region.querySwept(start, end, radius)...)
+	}
+
+	return hits
+}
+
+// sweepRect returns the first fraction of the path from start to end
+// at which a circle of given radius touches rect.
+func sweepRect(start, end rl.Vector2, rect rl.Rectangle, radius float32) (float32, bool) {
+	dir := rl.Vector2Subtract(end, start)
+	axes := [2]struct{ origin, dir, min, max float32 }{
+		{start.X, dir.X, rect.X - radius, rect.X + rect.Width + radius},
+		{start.Y, dir.Y, rect.Y - radius, rect.Y + rect.Height + radius},
+	}
+
+	tMin, tMax := float32(0), float32(1)
+	for _, axis := range axes {
+		if axis.dir == 0 {
+			if axis.origin < axis.min || axis.origin > axis.max {
+				return 0, false
+			}
+			continue
+		}
+
+		t1 := (axis.min - axis.origin) / axis.dir
+		t2 := (axis.max - axis.origin) / axis.dir
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tMin = max(tMin, t1)
+		tMax = min(tMax, t2)
+		if tMin > tMax {
+			return 0, false
+		}
+	}
+
+	return tMin, true
+}
+
+// Queries returns how many queries were made since the last ResetQueries call
+func (q *Quadtree) Queries() int {
+	return int(q.queries.Load())
+}
+
+func (q *Quadtree) ResetQueries() {
+	q.queries.Store(0)
+}
+
+// Len returns number of items stored in this node, without subregions
+func (q *Quadtree) Len() int {
+	return len(q.data)
+}
+
+// Walk calls fn for this node and every subregion, depth of the root is 0
+func (q *Quadtree) Walk(fn func(node *Quadtree, depth int)) {
+	q.walk(fn, 0)
+}
+
+func (q *Quadtree) walk(fn func(node *Quadtree, depth int), depth int) {
+	fn(q, depth)
+	for _, region := range q.Regions {
+		region.walk(fn, depth+1)
+	}
+}
+
 func (q *Quadtree) Clear() {
 	clear(q.data)
 	for _, region := range q.Regions {
@@ -0,0 +1,146 @@
+package quadtree
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var testBounds = rl.Rectangle{X: 0, Y: 0, Width: 10000, Height: 10000}
+
+func TestQuerySegment(t *testing.T) {
+	t.Run("very fast segment doesn't tunnel through small box", func(t *testing.T) {
+		q := NewQuadtree(testBounds, 4)
+		q.Insert(1, rl.Rectangle{X: 5000, Y: 5000, Width: 2, Height: 2}, "enemy")
+
+		hits := q.QuerySegment(rl.Vector2{X: 0, Y: 5001}, rl.Vector2{X: 9999, Y: 5001})
+		if len(hits) != 1 {
+			t.Fatalf("got %d hits, want 1", len(hits))
+		}
+		if hits[0].ID != 1 {
+			t.Errorf("got id %v, want 1", hits[0].ID)
+		}
+	})
+
+	t.Run("segment passing by misses", func(t *testing.T) {
+		q := NewQuadtree(testBounds, 4)
+		q.Insert(1, rl.Rectangle{X: 5000, Y: 5000, Width: 2, Height: 2}, "enemy")
+
+		hits := q.QuerySegment(rl.Vector2{X: 0, Y: 4990}, rl.Vector2{X: 9999, Y: 4990})
+		if len(hits) != 0 {
+			t.Errorf("got %d hits, want 0", len(hits))
+		}
+	})
+
+	t.Run("hits are ordered along the path", func(t *testing.T) {
+		q := NewQuadtree(testBounds, 1)
+		q.Insert(1, rl.Rectangle{X: 9000, Y: 100, Width: 10, Height: 10}, "far")
+		q.Insert(2, rl.Rectangle{X: 1000, Y: 100, Width: 10, Height: 10}, "near")
+		q.Insert(3, rl.Rectangle{X: 5000, Y: 100, Width: 10, Height: 10}, "middle")
+
+		hits := q.QuerySegment(rl.Vector2{X: 0, Y: 105}, rl.Vector2{X: 9999, Y: 105})
+		want := []int{2, 3, 1}
+		if len(hits) != len(want) {
+			t.Fatalf("got %d hits, want %d", len(hits), len(want))
+		}
+		for i := range want {
+			if hits[i].ID != want[i] {
+				t.Errorf("hit %d: got id %v, want %v", i, hits[i].ID, want[i])
+			}
+		}
+	})
+
+	t.Run("segment that stops before box misses", func(t *testing.T) {
+		q := NewQuadtree(testBounds, 4)
+		q.Insert(1, rl.Rectangle{X: 5000, Y: 5000, Width: 2, Height: 2}, "enemy")
+
+		hits := q.QuerySegment(rl.Vector2{X: 0, Y: 5001}, rl.Vector2{X: 4000, Y: 5001})
+		if len(hits) != 0 {
+			t.Errorf("got %d hits, want 0", len(hits))
+		}
+	})
+}
+
+func TestQuerySweptCircle(t *testing.T) {
+	t.Run("radius catches box segment would miss", func(t *testing.T) {
+		q := NewQuadtree(testBounds, 4)
+		q.Insert(1, rl.Rectangle{X: 5000, Y: 5000, Width: 2, Height: 2}, "enemy")
+
+		start := rl.Vector2{X: 0, Y: 4996}
+		end := rl.Vector2{X: 9999, Y: 4996}
+		if hits := q.QuerySegment(start, end); len(hits) != 0 {
+			t.Fatalf("segment: got %d hits, want 0", len(hits))
+		}
+		if hits := q.QuerySweptCircle(start, end, 5); len(hits) != 1 {
+			t.Errorf("swept circle: got %d hits, want 1", len(hits))
+		}
+	})
+
+	t.Run("diagonal high speed sweep reports entry point", func(t *testing.T) {
+		q := NewQuadtree(testBounds, 4)
+		q.Insert(1, rl.Rectangle{X: 4995, Y: 4995, Width: 10, Height: 10}, "enemy")
+
+		hits := q.QuerySweptCircle(rl.Vector2{X: 0, Y: 0}, rl.Vector2{X: 10000, Y: 10000}, 5)
+		if len(hits) != 1 {
+			t.Fatalf("got %d hits, want 1", len(hits))
+		}
+		got := hits[0].T
+		want := float32(0.499)
+		if got < want-0.001 || got > want+0.001 {
+			t.Errorf("got t %v, want %v", got, want)
+		}
+	})
+
+	t.Run("start inside box hits immediately", func(t *testing.T) {
+		q := NewQuadtree(testBounds, 4)
+		q.Insert(1, rl.Rectangle{X: 100, Y: 100, Width: 10, Height: 10}, "enemy")
+
+		hits := q.QuerySweptCircle(rl.Vector2{X: 105, Y: 105}, rl.Vector2{X: 9000, Y: 105}, 5)
+		if len(hits) != 1 {
+			t.Fatalf("got %d hits, want 1", len(hits))
+		}
+		if hits[0].T != 0 {
+			t.Errorf("got t %v, want 0", hits[0].T)
+		}
+	})
+}
+
+func TestWalk(t *testing.T) {
+	q := NewQuadtree(testBounds, 1)
+	q.Insert(1, rl.Rectangle{X: 100, Y: 100, Width: 10, Height: 10}, "nw")
+	q.Insert(2, rl.Rectangle{X: 9000, Y: 9000, Width: 10, Height: 10}, "se")
+
+	nodes, items, maxDepth := 0, 0, 0
+	q.Walk(func(node *Quadtree, depth int) {
+		nodes++
+		items += node.Len()
+		maxDepth = max(maxDepth, depth)
+	})
+
+	if nodes != 5 {
+		t.Errorf("got %d nodes, want 5", nodes)
+	}
+	if items != 2 {
+		t.Errorf("got %d items, want 2", items)
+	}
+	if maxDepth != 1 {
+		t.Errorf("got max depth %d, want 1", maxDepth)
+	}
+}
+
+func TestQueries(t *testing.T) {
+	q := NewQuadtree(testBounds, 1)
+	q.Insert(1, rl.Rectangle{X: 100, Y: 100, Width: 10, Height: 10}, "nw")
+	q.Insert(2, rl.Rectangle{X: 9000, Y: 9000, Width: 10, Height: 10}, "se")
+
+	q.Query(testBounds)
+	q.QuerySegment(rl.Vector2{}, rl.Vector2{X: 10000, Y: 10000})
+	if got := q.Queries(); got != 2 {
+		t.Errorf("got %d queries, want 2", got)
+	}
+
+	q.ResetQueries()
+	if got := q.Queries(); got != 0 {
+		t.Errorf("got %d queries after reset, want 0", got)
+	}
+}
@@ -0,0 +1,65 @@
+// Package filewatch polls files for changes.
+//
+// It's meant to be called from the game loop, so changes are handled on the
+// main thread, where textures can be loaded.
+package filewatch
+
+import (
+	"os"
+	"slices"
+	"time"
+)
+
+type Watcher struct {
+	interval  time.Duration
+	lastCheck time.Time
+
+	paths    []string
+	modTimes map[string]time.Time
+}
+
+// New watches given files, missing files are reported when they appear
+func New(interval time.Duration, paths ...string) *Watcher {
+	w := &Watcher{
+		interval: interval,
+		paths:    paths,
+		modTimes: make(map[string]time.Time, len(paths)),
+	}
+	for _, path := range paths {
+		w.modTimes[path] = modTime(path)
+	}
+	w.lastCheck = time.Now()
+	return w
+}
+
+// Changed returns files that were modified since the previous call.
+// Files are checked at most once per interval, so it can be called every frame.
+func (w *Watcher) Changed() []string {
+	if time.Since(w.lastCheck) < w.interval {
+		return nil
+	}
+	w.lastCheck = time.Now()
+
+	var changed []string
+	for _, path := range w.paths {
+		mt := modTime(path)
+		if mt.Equal(w.modTimes[path]) {
+			continue
+		}
+		w.modTimes[path] = mt
+		if !mt.IsZero() {
+			changed = append(changed, path)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// modTime returns zero time for missing files
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,57 @@
+package filewatch
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestChanged(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.png")
+	missing := filepath.Join(dir, "missing.json")
+	if err := os.WriteFile(existing, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := New(0, existing, missing)
+	if changed := w.Changed(); len(changed) != 0 {
+		t.Fatalf("got %v changed right after start, want nothing", changed)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(existing, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(missing, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{existing, missing}
+	slices.Sort(want)
+	if changed := w.Changed(); !slices.Equal(changed, want) {
+		t.Errorf("got %v, want %v", changed, want)
+	}
+	if changed := w.Changed(); len(changed) != 0 {
+		t.Errorf("got %v on second call, want nothing", changed)
+	}
+}
+
+func TestChangedRespectsInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "balance.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := New(time.Hour, path)
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed := w.Changed(); len(changed) != 0 {
+		t.Errorf("got %v before interval passed, want nothing", changed)
+	}
+}
@@ -0,0 +1,406 @@
+// Package navgrid splits an area into square cells, some of which are blocked,
+// and finds paths, lines of sight and distance fields on them.
+package navgrid
+
+import (
+	"container/heap"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Cell is a column and a row of the grid
+type Cell struct {
+	X, Y int
+}
+
+type Grid struct {
+	Bounds   rl.Rectangle
+	CellSize float32
+
+	cols, rows int
+	blocked    []bool
+}
+
+func New(bounds rl.Rectangle, cellSize float32) *Grid {
+	cols := int(math.Ceil(float64(bounds.Width / cellSize)))
+	rows := int(math.Ceil(float64(bounds.Height / cellSize)))
+	return &Grid{
+		Bounds:   bounds,
+		CellSize: cellSize,
+		cols:     cols,
+		rows:     rows,
+		blocked:  make([]bool, cols*rows),
+	}
+}
+
+func (g *Grid) Cols() int {
+	return g.cols
+}
+
+func (g *Grid) Rows() int {
+	return g.rows
+}
+
+// CellAt returns cell that contains pos, cell can be outside of the grid
+func (g *Grid) CellAt(pos rl.Vector2) Cell {
+	return Cell{
+		X: int(math.Floor(float64((pos.X - g.Bounds.X) / g.CellSize))),
+		Y: int(math.Floor(float64((pos.Y - g.Bounds.Y) / g.CellSize))),
+	}
+}
+
+func (g *Grid) Center(c Cell) rl.Vector2 {
+	return rl.Vector2{
+		X: g.Bounds.X + (float32(c.X)+0.5)*g.CellSize,
+		Y: g.Bounds.Y + (float32(c.Y)+0.5)*g.CellSize,
+	}
+}
+
+// CellBounds returns rectangle covered by the cell
+func (g *Grid) CellBounds(c Cell) rl.Rectangle {
+	return rl.Rectangle{
+		X:      g.Bounds.X + float32(c.X)*g.CellSize,
+		Y:      g.Bounds.Y + float32(c.Y)*g.CellSize,
+		Width:  g.CellSize,
+		Height: g.CellSize,
+	}
+}
+
+func (g *Grid) Inside(c Cell) bool {
+	return c.X >= 0 && c.X < g.cols && c.Y >= 0 && c.Y < g.rows
+}
+
+// Blocked reports whether cell is blocked, cells outside of the grid are never blocked
+func (g *Grid) Blocked(c Cell) bool {
+	return g.Inside(c) && g.blocked[c.Y*g.cols+c.X]
+}
+
+// Block blocks every cell rect touches
+func (g *Grid) Block(rect rl.Rectangle) {
+	g.eachCell(rect, func(c Cell) bool {
+		if g.Inside(c) {
+			g.blocked[c.Y*g.cols+c.X] = true
+		}
+		return true
+	})
+}
+
+// Overlaps reports whether rect touches any blocked cell
+func (g *Grid) Overlaps(rect rl.Rectangle) bool {
+	overlaps := false
+	g.eachCell(rect, func(c Cell) bool {
+		overlaps = g.Blocked(c)
+		return !overlaps
+	})
+	return overlaps
+}
+
+// eachCell calls fn for cells under rect until fn returns false.
+// Rect that only touches the edge of a cell isn't considered to be in it.
+func (g *Grid) eachCell(rect rl.Rectangle, fn func(c Cell) bool) {
+	const edge = 0.001
+	from := g.CellAt(rl.Vector2{X: rect.X + edge, Y: rect.Y + edge})
+	to := g.CellAt(rl.Vector2{X: rect.X + rect.Width - edge, Y: rect.Y + rect.Height - edge})
+	for y := from.Y; y <= to.Y; y++ {
+		for x := from.X; x <= to.X; x++ {
+			if !fn(Cell{X: x, Y: y}) {
+				return
+			}
+		}
+	}
+}
+
+// Slide returns the part of move rect can make without entering blocked cells.
+// If the whole move is blocked, rect slides along the obstacle on one of the axes.
+// Rect that already overlaps blocked cells moves freely, so it can get out.
+func (g *Grid) Slide(rect rl.Rectangle, move rl.Vector2) rl.Vector2 {
+	if g.Overlaps(rect) {
+		return move
+	}
+	moved := func(m rl.Vector2) bool {
+		return !g.Overlaps(rl.Rectangle{X: rect.X + m.X, Y: rect.Y + m.Y, Width: rect.Width, Height: rect.Height})
+	}
+	if moved(move) {
+		return move
+	}
+	// slide along the axis that keeps more of the move
+	alongX := rl.Vector2{X: move.X}
+	alongY := rl.Vector2{Y: move.Y}
+	if math.Abs(float64(move.Y)) > math.Abs(float64(move.X)) {
+		alongX, alongY = alongY, alongX
+	}
+	if moved(alongX) {
+		return alongX
+	}
+	if moved(alongY) {
+		return alongY
+	}
+	return rl.Vector2{}
+}
+
+// LineOfSight reports whether segment from one point to another doesn't cross blocked cells.
+// Cells are walked in order the segment crosses them, so thin walls can't be skipped.
+func (g *Grid) LineOfSight(from, to rl.Vector2) bool {
+	x0 := float64((from.X - g.Bounds.X) / g.CellSize)
+	y0 := float64((from.Y - g.Bounds.Y) / g.CellSize)
+	x1 := float64((to.X - g.Bounds.X) / g.CellSize)
+	y1 := float64((to.Y - g.Bounds.Y) / g.CellSize)
+
+	c := Cell{X: int(math.Floor(x0)), Y: int(math.Floor(y0))}
+	stepX, tMaxX, tDeltaX := traversal(x0, x1)
+	stepY, tMaxY, tDeltaY := traversal(y0, y1)
+	for {
+		if g.Blocked(c) {
+			return false
+		}
+		if tMaxX > 1 && tMaxY > 1 {
+			return true
+		}
+		if tMaxX < tMaxY {
+			c.X += stepX
+			tMaxX += tDeltaX
+		} else {
+			c.Y += stepY
+			tMaxY += tDeltaY
+		}
+	}
+}
+
+// traversal returns direction of walking along one axis, fraction of the segment
+// at which the first cell border is crossed and fraction between borders
+func traversal(from, to float64) (step int, tMax, tDelta float64) {
+	d := to - from
+	switch {
+	case d > 0:
+		return 1, (math.Floor(from) + 1 - from) / d, 1 / d
+	case d < 0:
+		return -1, (from - math.Floor(from)) / -d, 1 / -d
+	default:
+		return 0, math.Inf(1), math.Inf(1)
+	}
+}
+
+// Path finds the shortest path from one point to another with A*.
+// Path consists of centers of cells to walk through and ends with to itself.
+// Diagonal moves don't cut corners of blocked cells.
+// Path is nil if to can't be reached or either point is outside of the grid.
+func (g *Grid) Path(from, to rl.Vector2) []rl.Vector2 {
+	start, goal := g.CellAt(from), g.CellAt(to)
+	if !g.Inside(start) || !g.Inside(goal) || g.Blocked(goal) {
+		return nil
+	}
+	if start == goal {
+		return []rl.Vector2{to}
+	}
+
+	index := func(c Cell) int { return c.Y*g.cols + c.X }
+	cost := make([]float64, len(g.blocked))
+	for i := range cost {
+		cost[i] = math.Inf(1)
+	}
+	cameFrom := make([]int, len(g.blocked))
+	cost[index(start)] = 0
+
+	open := &cellQueue{{cell: start, priority: heuristic(start, goal)}}
+	for open.Len() > 0 {
+		current := heap.Pop(open).(queuedCell).cell
+		if current == goal {
+			return g.reconstruct(cameFrom, index(start), index(goal), to)
+		}
+		for _, n := range neighbours {
+			next := Cell{X: current.X + n.X, Y: current.Y + n.Y}
+			step, ok := g.step(current, n)
+			if !ok {
+				continue
+			}
+			nextCost := cost[index(current)] + step
+			if nextCost >= cost[index(next)] {
+				continue
+			}
+			cost[index(next)] = nextCost
+			cameFrom[index(next)] = index(current)
+			heap.Push(open, queuedCell{cell: next, priority: nextCost + heuristic(next, goal)})
+		}
+	}
+	return nil
+}
+
+// step returns length of the move from cell to its neighbour in direction n.
+// Moves into blocked cells, out of the grid and diagonal moves that cut corners are not allowed.
+func (g *Grid) step(from, n Cell) (float64, bool) {
+	to := Cell{X: from.X + n.X, Y: from.Y + n.Y}
+	if !g.Inside(to) || g.Blocked(to) {
+		return 0, false
+	}
+	if n.X == 0 || n.Y == 0 {
+		return 1, true
+	}
+	if g.Blocked(Cell{X: from.X + n.X, Y: from.Y}) || g.Blocked(Cell{X: from.X, Y: from.Y + n.Y}) {
+		return 0, false
+	}
+	return math.Sqrt2, true
+}
+
+func (g *Grid) reconstruct(cameFrom []int, start, goal int, to rl.Vector2) []rl.Vector2 {
+	path := []rl.Vector2{to}
+	for i := cameFrom[goal]; i != start; i = cameFrom[i] {
+		path = append(path, g.Center(Cell{X: i % g.cols, Y: i / g.cols}))
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+var neighbours = []Cell{
+	{X: 1}, {X: -1}, {Y: 1}, {Y: -1},
+	{X: 1, Y: 1}, {X: 1, Y: -1}, {X: -1, Y: 1}, {X: -1, Y: -1},
+}
+
+// heuristic is octile distance, exact distance on grid without obstacles
+func heuristic(from, to Cell) float64 {
+	dx := math.Abs(float64(from.X - to.X))
+	dy := math.Abs(float64(from.Y - to.Y))
+	return dx + dy + (math.Sqrt2-2)*min(dx, dy)
+}
+
+// Field is a distance from every cell to the nearest of the goals.
+// It's built once for all entities walking to the same goals,
+// so each of them only has to look at neighbouring cells to find its way.
+type Field struct {
+	grid *Grid
+	dist []float64
+}
+
+// wallCost is extra cost of cells next to blocked ones, so entities that are wider
+// than a point keep away from walls and don't get stuck on corners when there is space
+const wallCost = 1
+
+// Field builds distance field with Dijkstra's algorithm from all goals at once.
+// Leaving a cell costs the step length multiplied by 1 plus extra cost returned by cost,
+// so entities walk around expensive areas when detour is cheaper.
+func (g *Grid) Field(goals []rl.Vector2, cost func(c Cell) float64) *Field {
+	f := &Field{grid: g, dist: make([]float64, len(g.blocked))}
+	for i := range f.dist {
+		f.dist[i] = math.Inf(1)
+	}
+	extra := make([]float64, len(g.blocked))
+	for i := range extra {
+		c := Cell{X: i % g.cols, Y: i / g.cols}
+		extra[i] = cost(c)
+		if g.nearBlocked(c) {
+			extra[i] += wallCost
+		}
+	}
+
+	open := &cellQueue{}
+	for _, pos := range goals {
+		c := g.CellAt(pos)
+		if !g.Inside(c) || g.Blocked(c) {
+			continue
+		}
+		f.dist[c.Y*g.cols+c.X] = 0
+		heap.Push(open, queuedCell{cell: c})
+	}
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(queuedCell)
+		if current.priority > f.dist[current.cell.Y*g.cols+current.cell.X] {
+			// cell was reached by a shorter path already
+			continue
+		}
+		for _, n := range neighbours {
+			step, ok := g.step(current.cell, n)
+			if !ok {
+				continue
+			}
+			next := Cell{X: current.cell.X + n.X, Y: current.cell.Y + n.Y}
+			// search goes from goals, so next is the cell entity leaves when walking this step,
+			// and goals inside expensive areas don't cost more to enter
+			i := next.Y*g.cols + next.X
+			nextDist := current.priority + step*(1+extra[i])
+			if nextDist >= f.dist[i] {
+				continue
+			}
+			f.dist[i] = nextDist
+			heap.Push(open, queuedCell{cell: next, priority: nextDist})
+		}
+	}
+	return f
+}
+
+func (g *Grid) nearBlocked(c Cell) bool {
+	for _, n := range neighbours {
+		if g.Blocked(Cell{X: c.X + n.X, Y: c.Y + n.Y}) {
+			return true
+		}
+	}
+	return false
+}
+
+// Distance returns distance from pos to the nearest goal in cells,
+// it's infinite if no goal can be reached from pos
+func (f *Field) Distance(pos rl.Vector2) float64 {
+	c := f.grid.CellAt(pos)
+	if !f.grid.Inside(c) {
+		return math.Inf(1)
+	}
+	return f.dist[c.Y*f.grid.cols+c.X]
+}
+
+// Direction returns normalized direction from pos towards the nearest goal,
+// it's the direction of the step that brings entity closer to the goal the most per pixel walked.
+// Entities in the same cell get the same direction, so they walk side by side instead of converging.
+// False is returned once pos is in the goal cell or no goal can be reached.
+func (f *Field) Direction(pos rl.Vector2) (rl.Vector2, bool) {
+	c := f.grid.CellAt(pos)
+	if !f.grid.Inside(c) {
+		return rl.Vector2{}, false
+	}
+	dist := f.dist[c.Y*f.grid.cols+c.X]
+	if dist == 0 || math.IsInf(dist, 1) {
+		return rl.Vector2{}, false
+	}
+	var bestStep Cell
+	var bestGain float64
+	for _, n := range neighbours {
+		step, ok := f.grid.step(c, n)
+		if !ok {
+			continue
+		}
+		// orthogonal steps go first, so they win ties with diagonal ones,
+		// tolerance keeps rounding errors from breaking ties
+		if gain := (dist - f.dist[(c.Y+n.Y)*f.grid.cols+c.X+n.X]) / step; gain > bestGain+1e-9 {
+			bestGain = gain
+			bestStep = n
+		}
+	}
+	if bestGain == 0 {
+		return rl.Vector2{}, false
+	}
+	return rl.Vector2Normalize(rl.Vector2{X: float32(bestStep.X), Y: float32(bestStep.Y)}), true
+}
+
+type queuedCell struct {
+	cell     Cell
+	priority float64
+}
+
+// cellQueue is a min-heap of cells by priority
+type cellQueue []queuedCell
+
+func (q cellQueue) Len() int { return len(q) }
+
+func (q cellQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+
+func (q cellQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *cellQueue) Push(x any) { *q = append(*q, x.(queuedCell)) }
+
+func (q *cellQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
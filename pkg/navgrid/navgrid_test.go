@@ -0,0 +1,233 @@
+package navgrid
+
+import (
+	"math"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// newTestGrid is 10x10 cells of 10 pixels with a vertical wall at column 5 from row 0 to row 8
+func newTestGrid() *Grid {
+	g := New(rl.Rectangle{Width: 100, Height: 100}, 10)
+	g.Block(rl.Rectangle{X: 50, Y: 0, Width: 10, Height: 90})
+	return g
+}
+
+func TestBlock(t *testing.T) {
+	g := newTestGrid()
+
+	if !g.Blocked(Cell{X: 5, Y: 0}) || !g.Blocked(Cell{X: 5, Y: 8}) {
+		t.Errorf("wall cells are not blocked")
+	}
+	if g.Blocked(Cell{X: 4, Y: 0}) || g.Blocked(Cell{X: 6, Y: 0}) || g.Blocked(Cell{X: 5, Y: 9}) {
+		t.Errorf("cells next to the wall are blocked")
+	}
+	if g.Blocked(Cell{X: -1, Y: 0}) {
+		t.Errorf("cell outside of the grid is blocked")
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	g := newTestGrid()
+
+	tests := []struct {
+		name string
+		rect rl.Rectangle
+		want bool
+	}{
+		{"free", rl.Rectangle{X: 10, Y: 10, Width: 20, Height: 20}, false},
+		{"inside wall", rl.Rectangle{X: 52, Y: 10, Width: 2, Height: 2}, true},
+		{"partly in wall", rl.Rectangle{X: 45, Y: 10, Width: 10, Height: 10}, true},
+		{"touches wall edge", rl.Rectangle{X: 40, Y: 10, Width: 10, Height: 10}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.Overlaps(tt.rect); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlide(t *testing.T) {
+	g := newTestGrid()
+	rect := rl.Rectangle{X: 40, Y: 10, Width: 10, Height: 10}
+
+	t.Run("free move", func(t *testing.T) {
+		move := rl.Vector2{X: -3, Y: 2}
+		if got := g.Slide(rect, move); got != move {
+			t.Errorf("got %v, want %v", got, move)
+		}
+	})
+
+	t.Run("slides along wall", func(t *testing.T) {
+		got := g.Slide(rect, rl.Vector2{X: 3, Y: 2})
+		want := rl.Vector2{Y: 2}
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("stops at wall", func(t *testing.T) {
+		got := g.Slide(rect, rl.Vector2{X: 3})
+		if got != (rl.Vector2{}) {
+			t.Errorf("got %v, want zero move", got)
+		}
+	})
+}
+
+func TestLineOfSight(t *testing.T) {
+	g := newTestGrid()
+
+	tests := []struct {
+		name     string
+		from, to rl.Vector2
+		want     bool
+	}{
+		{"same side", rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 45, Y: 85}, true},
+		{"through wall", rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 95, Y: 5}, false},
+		{"diagonal through wall", rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 95, Y: 80}, false},
+		{"under wall", rl.Vector2{X: 5, Y: 95}, rl.Vector2{X: 95, Y: 95}, true},
+		{"same point", rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 5, Y: 5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.LineOfSight(tt.from, tt.to); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got := g.LineOfSight(tt.to, tt.from); got != tt.want {
+				t.Errorf("reversed: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	g := newTestGrid()
+
+	t.Run("goes around wall", func(t *testing.T) {
+		from := rl.Vector2{X: 45, Y: 5}
+		to := rl.Vector2{X: 65, Y: 5}
+
+		path := g.Path(from, to)
+
+		if len(path) == 0 {
+			t.Fatal("no path")
+		}
+		if path[len(path)-1] != to {
+			t.Errorf("got last waypoint %v, want %v", path[len(path)-1], to)
+		}
+		prev := from
+		for _, p := range path {
+			if !g.LineOfSight(prev, p) {
+				t.Fatalf("path goes through wall from %v to %v", prev, p)
+			}
+			prev = p
+		}
+		if below := g.CellAt(path[len(path)/2]); below.Y != 9 {
+			t.Errorf("got middle of path in row %d, want 9", below.Y)
+		}
+	})
+
+	t.Run("same cell", func(t *testing.T) {
+		to := rl.Vector2{X: 8, Y: 8}
+		path := g.Path(rl.Vector2{X: 2, Y: 2}, to)
+		if len(path) != 1 || path[0] != to {
+			t.Errorf("got %v, want only %v", path, to)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		g := newTestGrid()
+		g.Block(rl.Rectangle{X: 50, Y: 90, Width: 10, Height: 10})
+
+		if path := g.Path(rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 95, Y: 5}); path != nil {
+			t.Errorf("got %v, want nil", path)
+		}
+	})
+
+	t.Run("outside of grid", func(t *testing.T) {
+		if path := g.Path(rl.Vector2{X: -5, Y: 5}, rl.Vector2{X: 5, Y: 5}); path != nil {
+			t.Errorf("got %v, want nil", path)
+		}
+	})
+}
+
+func noCost(Cell) float64 { return 0 }
+
+func TestField(t *testing.T) {
+	t.Run("distance to nearest goal", func(t *testing.T) {
+		g := New(rl.Rectangle{Width: 100, Height: 100}, 10)
+		f := g.Field([]rl.Vector2{{X: 5, Y: 5}, {X: 95, Y: 5}}, noCost)
+
+		tests := []struct {
+			pos  rl.Vector2
+			want float64
+		}{
+			{rl.Vector2{X: 5, Y: 5}, 0},
+			{rl.Vector2{X: 35, Y: 5}, 3},
+			{rl.Vector2{X: 65, Y: 5}, 3},
+			{rl.Vector2{X: 25, Y: 25}, 2 * math.Sqrt2},
+		}
+		for _, tt := range tests {
+			if got := f.Distance(tt.pos); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("at %v: got %v, want %v", tt.pos, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("direction goes around wall", func(t *testing.T) {
+		g := newTestGrid()
+		f := g.Field([]rl.Vector2{{X: 65, Y: 5}}, noCost)
+
+		pos := rl.Vector2{X: 45, Y: 5}
+		for range 100 {
+			dir, ok := f.Direction(pos)
+			if !ok {
+				break
+			}
+			next := rl.Vector2Add(pos, rl.Vector2Scale(dir, 5))
+			if !g.LineOfSight(pos, next) {
+				t.Fatalf("walked through wall from %v to %v", pos, next)
+			}
+			pos = next
+		}
+		if got := g.CellAt(pos); got != (Cell{X: 6, Y: 0}) {
+			t.Errorf("got to cell %v, want goal cell {6 0}", got)
+		}
+	})
+
+	t.Run("expensive cells are avoided", func(t *testing.T) {
+		g := New(rl.Rectangle{Width: 100, Height: 100}, 10)
+		// column 5 is expensive everywhere but the bottom row
+		expensive := func(c Cell) float64 {
+			if c.X == 5 && c.Y < 9 {
+				return 100
+			}
+			return 0
+		}
+		f := g.Field([]rl.Vector2{{X: 65, Y: 5}}, expensive)
+
+		if got := f.Distance(rl.Vector2{X: 45, Y: 5}); got > 20 {
+			t.Errorf("got distance %v, want path around expensive cells", got)
+		}
+		dir, ok := f.Direction(rl.Vector2{X: 45, Y: 5})
+		if !ok || dir.Y <= 0 {
+			t.Errorf("got direction %v, want it to go down around expensive cells", dir)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		g := newTestGrid()
+		g.Block(rl.Rectangle{X: 50, Y: 90, Width: 10, Height: 10})
+		f := g.Field([]rl.Vector2{{X: 95, Y: 5}}, noCost)
+
+		if d := f.Distance(rl.Vector2{X: 5, Y: 5}); !math.IsInf(d, 1) {
+			t.Errorf("got distance %v, want infinity", d)
+		}
+		if _, ok := f.Direction(rl.Vector2{X: 5, Y: 5}); ok {
+			t.Errorf("got direction to unreachable goal")
+		}
+	})
+}
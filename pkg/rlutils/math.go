@@ -17,3 +17,30 @@ func ScaleValueToSize[T constraints.Float | constraints.Integer](
 func RandomFloat(from, to float32) float32 {
 	return from + (to-from)*rand.Float32()
 }
+
+// PickWeighted returns index of the weight roll in [0, 1) lands on, false if every weight is zero.
+// Negative weights count as zero.
+func PickWeighted(weights []float32, roll float32) (int, bool) {
+	var total float32
+	for _, w := range weights {
+		total += max(w, 0)
+	}
+	if total == 0 {
+		return 0, false
+	}
+
+	target := roll * total
+	lastPicked := -1
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		lastPicked = i
+		if target < w {
+			return i, true
+		}
+		target -= w
+	}
+	// roll close to 1 can overshoot because of float rounding
+	return lastPicked, true
+}
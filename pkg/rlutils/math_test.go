@@ -13,3 +13,24 @@ func TestScaleValueToSize(t *testing.T) {
 		}
 	})
 }
+
+func TestPickWeighted(t *testing.T) {
+	weights := []float32{1, 0, -1, 3}
+	for _, tc := range []struct {
+		roll float32
+		want int
+	}{
+		{roll: 0, want: 0},
+		{roll: 0.24, want: 0},
+		{roll: 0.26, want: 3},
+		{roll: 0.9999999, want: 3},
+	} {
+		if got, ok := PickWeighted(weights, tc.roll); !ok || got != tc.want {
+			t.Errorf("roll %v: got %d, want %d", tc.roll, got, tc.want)
+		}
+	}
+
+	if _, ok := PickWeighted([]float32{0, -1}, 0.5); ok {
+		t.Errorf("picked from zero weights")
+	}
+}
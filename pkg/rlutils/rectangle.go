@@ -0,0 +1,19 @@
+package rlutils
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+func RectangleCenter(rec rl.Rectangle) rl.Vector2 {
+	return rl.Vector2{X: rec.X + rec.Width/2, Y: rec.Y + rec.Height/2}
+}
+
+// RectanglesUnion returns the smallest rectangle that contains both rectangles
+func RectanglesUnion(a, b rl.Rectangle) rl.Rectangle {
+	x := min(a.X, b.X)
+	y := min(a.Y, b.Y)
+	return rl.Rectangle{
+		X:      x,
+		Y:      y,
+		Width:  max(a.X+a.Width, b.X+b.Width) - x,
+		Height: max(a.Y+a.Height, b.Y+b.Height) - y,
+	}
+}